@@ -2,18 +2,62 @@ package app
 
 import (
 	"database/sql"
+	"github.com/go-sql-driver/mysql"
+	"golang-restful-api/config"
 	"golang-restful-api/helper"
+	"golang-restful-api/repository"
+	"strings"
 	"time"
 )
 
-func NewDB() *sql.DB {
-	db, err := sql.Open("mysql", "devtest:root@tcp(localhost:3306)/dev")
+func NewDialect(config config.DatabaseConfig) repository.Dialect {
+	dialect, err := repository.NewDialect(config.Driver)
 	helper.PanicIfError(err)
 
-	db.SetMaxIdleConns(5)
-	db.SetMaxIdleConns(50)
-	db.SetConnMaxIdleTime(10 * time.Minute)
-	db.SetConnMaxLifetime(60 * time.Minute)
+	return dialect
+}
+
+func NewDB(config config.DatabaseConfig, dialect repository.Dialect) *sql.DB {
+	dsn := config.DSN
+	switch dialect.Name() {
+	case "mysql":
+		dsn = mysqlDSN(dsn)
+	case "sqlite":
+		dsn = sqliteDSN(dsn)
+	}
+
+	db, err := sql.Open(dialect.DriverName(), dsn)
+	helper.PanicIfError(err)
+
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
 
 	return db
 }
+
+// mysqlDSN makes the driver return DATETIME columns as time.Time in UTC, as
+// the other drivers do.
+func mysqlDSN(dsn string) string {
+	mysqlConfig, err := mysql.ParseDSN(dsn)
+	helper.PanicIfError(err)
+
+	mysqlConfig.ParseTime = true
+	mysqlConfig.Loc = time.UTC
+
+	return mysqlConfig.FormatDSN()
+}
+
+// sqliteDSN turns on foreign keys, which SQLite leaves unenforced unless
+// asked for on every connection, unless the DSN decides otherwise.
+func sqliteDSN(dsn string) string {
+	if strings.Contains(dsn, "_foreign_keys=") || strings.Contains(dsn, "_fk=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&_foreign_keys=1"
+	}
+
+	return dsn + "?_foreign_keys=1"
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"fmt"
+	"golang-restful-api/config"
+	"golang-restful-api/ratelimit"
+	"strings"
+)
+
+// NewRateLimitRules rejects route limits for routes the router does not
+// have, which would otherwise never apply.
+func NewRateLimitRules(config config.RateLimitConfig) (ratelimit.Rules, error) {
+	rules := ratelimit.Rules{
+		Default: newLimit(config.Default),
+		Keys:    map[string]ratelimit.Limit{},
+		Routes:  map[string]ratelimit.Limit{},
+	}
+
+	for client, limit := range config.Keys {
+		rules.Keys[client] = newLimit(limit)
+	}
+
+	for route, limit := range config.Routes {
+		method, pattern, _ := strings.Cut(route, " ")
+		if !Policy.Declares(method, pattern) {
+			return rules, fmt.Errorf("config: rate_limit.routes: unknown route %q", route)
+		}
+		rules.Routes[route] = newLimit(limit)
+	}
+
+	return rules, nil
+}
+
+func newLimit(config config.LimitConfig) ratelimit.Limit {
+	return ratelimit.Limit{Requests: config.Requests, Per: config.Per, Burst: config.Burst}
+}
@@ -2,20 +2,137 @@ package app
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"golang-restful-api/auth"
 	"golang-restful-api/controller"
 	"golang-restful-api/exception"
+	"golang-restful-api/middleware"
+	"net/http"
+	"strings"
 )
 
-func NewRouter(categoryController controller.CategoryController) *httprouter.Router {
+// HealthPaths are probed by load balancers and never rate limited.
+var HealthPaths = []string{"/healthz", "/readyz"}
+
+// PublicPaths are served without authentication.
+var PublicPaths = append([]string{"/api/auth/login", "/api/auth/register"}, HealthPaths...)
+
+// Policy lists the scopes every route requires.
+var Policy = auth.Policy{
+	"GET /healthz": nil,
+	"GET /readyz":  nil,
+
+	"GET /api/categories":                {auth.ScopeCategoriesRead},
+	"GET /api/categories/:categoryId":    {auth.ScopeCategoriesRead},
+	"POST /api/categories":               {auth.ScopeCategoriesWrite},
+	"PUT /api/categories/:categoryId":    {auth.ScopeCategoriesWrite},
+	"DELETE /api/categories/:categoryId": {auth.ScopeCategoriesDelete},
+
+	"POST /api/categories/:categoryId/restore": {auth.ScopeCategoriesDelete},
+	"POST /api/categories/:categoryId/purge":   {auth.ScopeCategoriesDelete, auth.ScopeAdmin},
+
+	"GET /api/categories/tree":                  {auth.ScopeCategoriesRead},
+	"GET /api/categories/by-slug/:slug":         {auth.ScopeCategoriesRead},
+	"GET /api/categories/:categoryId/children":  {auth.ScopeCategoriesRead},
+	"GET /api/categories/:categoryId/ancestors": {auth.ScopeCategoriesRead},
+	"GET /api/categories/:categoryId/tree":      {auth.ScopeCategoriesRead},
+	"POST /api/categories/:categoryId/move":     {auth.ScopeCategoriesWrite},
+
+	"GET /api/apikeys":                   {auth.ScopeAdmin},
+	"GET /api/apikeys/:apiKeyId":         {auth.ScopeAdmin},
+	"POST /api/apikeys":                  {auth.ScopeAdmin},
+	"POST /api/apikeys/:apiKeyId/rotate": {auth.ScopeAdmin},
+	"DELETE /api/apikeys/:apiKeyId":      {auth.ScopeAdmin},
+
+	"GET /api/users":                 {auth.ScopeAdmin},
+	"GET /api/users/:userId":         {auth.ScopeAdmin},
+	"POST /api/users":                {auth.ScopeAdmin},
+	"POST /api/users/:userId/unlock": {auth.ScopeAdmin},
+
+	"GET /api/audit": {auth.ScopeAdmin},
+
+	"POST /api/auth/login":    nil,
+	"POST /api/auth/register": nil,
+	"POST /api/auth/logout":   nil,
+	"PUT /api/auth/password":  nil,
+}
+
+// categoryResources are the static segments below /api/categories/. They
+// are served by a router of their own because httprouter cannot have a
+// static segment where /api/categories/:categoryId has its wildcard.
+var categoryResources = map[string]bool{"tree": true, "by-slug": true}
+
+func isCategoryResource(path string) bool {
+	rest, ok := strings.CutPrefix(path, "/api/categories/")
+	if !ok {
+		return false
+	}
+	segment, _, _ := strings.Cut(rest, "/")
+
+	return categoryResources[segment]
+}
+
+type splitRouter struct {
+	router         *httprouter.Router
+	resourceRouter *httprouter.Router
+}
+
+func (split *splitRouter) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if isCategoryResource(request.URL.Path) {
+		split.resourceRouter.ServeHTTP(writer, request)
+		return
+	}
+
+	split.router.ServeHTTP(writer, request)
+}
+
+func NewRouter(categoryController controller.CategoryController, apiKeyController controller.ApiKeyController, userController controller.UserController, auditController controller.AuditController, healthController controller.HealthController) http.Handler {
 	router := httprouter.New()
+	resourceRouter := httprouter.New()
+	handle := func(method string, path string, handle httprouter.Handle) {
+		target := router
+		if isCategoryResource(path) {
+			target = resourceRouter
+		}
+		target.Handle(method, path, middleware.Authorize(Policy, method, path, handle))
+	}
+
+	handle("GET", "/healthz", healthController.Liveness)
+	handle("GET", "/readyz", healthController.Readiness)
+
+	handle("GET", "/api/categories", categoryController.GetAllCategory)
+	handle("GET", "/api/categories/:categoryId", categoryController.GetCategoryById)
+	handle("GET", "/api/categories/tree", categoryController.GetCategoryTree)
+	handle("GET", "/api/categories/by-slug/:slug", categoryController.GetCategoryBySlug)
+	handle("POST", "/api/categories", categoryController.CreateCategory)
+	handle("PUT", "/api/categories/:categoryId", categoryController.UpdateCategory)
+	handle("DELETE", "/api/categories/:categoryId", categoryController.DeleteCategory)
+	handle("POST", "/api/categories/:categoryId/restore", categoryController.RestoreCategory)
+	handle("POST", "/api/categories/:categoryId/purge", categoryController.PurgeCategory)
+	handle("GET", "/api/categories/:categoryId/children", categoryController.GetCategoryChildren)
+	handle("GET", "/api/categories/:categoryId/ancestors", categoryController.GetCategoryAncestors)
+	handle("GET", "/api/categories/:categoryId/tree", categoryController.GetCategorySubtree)
+	handle("POST", "/api/categories/:categoryId/move", categoryController.MoveCategory)
+
+	handle("GET", "/api/apikeys", apiKeyController.GetAllApiKey)
+	handle("GET", "/api/apikeys/:apiKeyId", apiKeyController.GetApiKeyById)
+	handle("POST", "/api/apikeys", apiKeyController.CreateApiKey)
+	handle("POST", "/api/apikeys/:apiKeyId/rotate", apiKeyController.RotateApiKey)
+	handle("DELETE", "/api/apikeys/:apiKeyId", apiKeyController.RevokeApiKey)
+
+	handle("GET", "/api/users", userController.GetAllUser)
+	handle("GET", "/api/users/:userId", userController.GetUserById)
+	handle("POST", "/api/users", userController.CreateUser)
+	handle("POST", "/api/users/:userId/unlock", userController.UnlockUser)
+
+	handle("GET", "/api/audit", auditController.GetAllAuditEntry)
 
-	router.GET("/api/categories", categoryController.GetAllCategory)
-	router.GET("/api/categories/:categoryId", categoryController.GetCategoryById)
-	router.POST("/api/categories", categoryController.CreateCategory)
-	router.PUT("/api/categories/:categoryId", categoryController.UpdateCategory)
-	router.DELETE("/api/categories/:categoryId", categoryController.DeleteCategory)
+	handle("POST", "/api/auth/login", userController.Login)
+	handle("POST", "/api/auth/register", userController.Register)
+	handle("POST", "/api/auth/logout", userController.Logout)
+	handle("PUT", "/api/auth/password", userController.ChangePassword)
 
 	router.PanicHandler = exception.ErrorHandler
+	resourceRouter.PanicHandler = exception.ErrorHandler
 
-	return router
+	return &splitRouter{router: router, resourceRouter: resourceRouter}
 }
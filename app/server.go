@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"golang-restful-api/config"
+	"log"
+	"net"
+	"net/http"
+)
+
+func NewServer(config config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              config.Address,
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+}
+
+// Serve accepts connections on listener until ctx is cancelled, then stops
+// accepting new connections, drains in-flight requests and runs the shutdown
+// hooks. The server itself is registered last so it is the first to stop.
+// A server with a TLSConfig serves HTTPS with the certificates it provides.
+func Serve(ctx context.Context, server *http.Server, listener net.Listener, shutdown *Shutdown) error {
+	shutdown.Register("http server", server.Shutdown)
+	shutdown.draining.Store(true)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			serveErr <- server.ServeTLS(listener, "", "")
+			return
+		}
+		serveErr <- server.Serve(listener)
+	}()
+
+	log.Printf("server: listening on %s", listener.Addr())
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return errors.Join(err, shutdown.Run())
+		}
+	case <-ctx.Done():
+	}
+
+	return shutdown.Run()
+}
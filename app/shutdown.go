@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type ShutdownHook func(ctx context.Context) error
+
+type shutdownHook struct {
+	name string
+	hook ShutdownHook
+}
+
+// Shutdown collects the callbacks that release background components and
+// runs them, most recently registered first, within a single deadline.
+// Delay keeps serving after readiness starts failing so that load balancers
+// stop routing new requests before the listener closes. The hooks are only
+// logged once a server drains, not when a one-off command exits.
+type Shutdown struct {
+	Timeout      time.Duration
+	Delay        time.Duration
+	mutex        sync.Mutex
+	hooks        []shutdownHook
+	shuttingDown atomic.Bool
+	draining     atomic.Bool
+}
+
+func NewShutdown(timeout time.Duration) *Shutdown {
+	return &Shutdown{Timeout: timeout}
+}
+
+func (shutdown *Shutdown) Register(name string, hook ShutdownHook) {
+	shutdown.mutex.Lock()
+	defer shutdown.mutex.Unlock()
+
+	shutdown.hooks = append(shutdown.hooks, shutdownHook{name: name, hook: hook})
+}
+
+func (shutdown *Shutdown) IsShuttingDown() bool {
+	return shutdown.shuttingDown.Load()
+}
+
+func (shutdown *Shutdown) Run() error {
+	if !shutdown.shuttingDown.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	time.Sleep(shutdown.Delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdown.Timeout)
+	defer cancel()
+
+	shutdown.mutex.Lock()
+	hooks := shutdown.hooks
+	shutdown.mutex.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if shutdown.draining.Load() {
+			log.Printf("shutdown: stopping %s", hooks[i].name)
+		}
+
+		err := hooks[i].hook(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("shutdown %s: %w", hooks[i].name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"golang-restful-api/config"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var tlsClientAuth = map[string]tls.ClientAuthType{
+	"none":     tls.NoClientCert,
+	"optional": tls.VerifyClientCertIfGiven,
+	"require":  tls.RequireAndVerifyClientCert,
+}
+
+// TLSReloader holds the certificate and client CA pool of server.tls and
+// swaps in new ones when the files change, so certificates are rotated
+// without a restart. A failed reload keeps the previous configuration.
+type TLSReloader struct {
+	config       config.TLSConfig
+	cipherSuites []uint16
+	current      atomic.Pointer[tls.Config]
+	mutex        sync.Mutex
+	attempted    string
+}
+
+func NewTLSReloader(config config.TLSConfig) (*TLSReloader, error) {
+	cipherSuites, err := parseCipherSuites(config.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader := &TLSReloader{config: config, cipherSuites: cipherSuites}
+	_, err = reloader.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("config: server.tls.cipher_suites: unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// TLSConfig is the configuration to serve with. Every handshake picks up the
+// most recently loaded certificate and client CA pool.
+func (reloader *TLSReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &reloader.current.Load().Certificates[0], nil
+		},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			return reloader.current.Load(), nil
+		},
+	}
+}
+
+// Reload loads the files again if any of them changed since the last
+// attempt and reports whether a new configuration is in use.
+func (reloader *TLSReloader) Reload() (bool, error) {
+	reloader.mutex.Lock()
+	defer reloader.mutex.Unlock()
+
+	stamp, err := reloader.stamp()
+	if err != nil {
+		return false, err
+	}
+	if stamp == reloader.attempted {
+		return false, nil
+	}
+	// Files written one after the other are retried on the next change
+	// rather than on every poll in between.
+	reloader.attempted = stamp
+
+	loaded, err := reloader.load()
+	if err != nil {
+		return false, err
+	}
+	reloader.current.Store(loaded)
+
+	return true, nil
+}
+
+func (reloader *TLSReloader) files() []string {
+	files := []string{reloader.config.CertFile, reloader.config.KeyFile}
+	if reloader.config.ClientCAFile != "" {
+		files = append(files, reloader.config.ClientCAFile)
+	}
+
+	return files
+}
+
+func (reloader *TLSReloader) stamp() (string, error) {
+	stamp := ""
+	for _, file := range reloader.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", fmt.Errorf("tls: %w", err)
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+	}
+
+	return stamp, nil
+}
+
+func (reloader *TLSReloader) load() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(reloader.config.CertFile, reloader.config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: load %s: %w", reloader.config.CertFile, err)
+	}
+
+	loaded := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tlsVersions[reloader.config.MinVersion],
+		CipherSuites: reloader.cipherSuites,
+		ClientAuth:   tlsClientAuth[reloader.config.ClientAuth],
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if reloader.config.ClientCAFile != "" {
+		content, err := os.ReadFile(reloader.config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("tls: %s contains no certificates", reloader.config.ClientCAFile)
+		}
+		loaded.ClientCAs = pool
+	}
+
+	return loaded, nil
+}
+
+// Watch checks the files every interval until ctx is cancelled.
+func (reloader *TLSReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := reloader.Reload()
+			if err != nil {
+				log.Printf("tls: keeping the previous certificate: %s", err)
+			} else if reloaded {
+				log.Printf("tls: reloaded %s", reloader.config.CertFile)
+			}
+		}
+	}
+}
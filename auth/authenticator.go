@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+)
+
+const ApiKeyHeader = "X-API-Key"
+
+var (
+	// ErrNoCredentials means the request carries no credentials this
+	// authenticator understands, so the next one should be tried.
+	ErrNoCredentials = errors.New("auth: no credentials")
+	// ErrInvalidCredentials means the credentials were recognised but are
+	// wrong, expired or revoked.
+	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+)
+
+type Authenticator interface {
+	Authenticate(request *http.Request) (Identity, error)
+}
+
+// Challenger is implemented by authenticators that name their scheme in the
+// WWW-Authenticate header of a 401 response.
+type Challenger interface {
+	Challenge() string
+}
+
+// ApiKeyVerifier resolves a presented API key to the identity it was issued to.
+type ApiKeyVerifier interface {
+	VerifyApiKey(ctx context.Context, key string) (Identity, error)
+}
+
+type ApiKeyAuthenticator struct {
+	Verifier ApiKeyVerifier
+}
+
+func NewApiKeyAuthenticator(verifier ApiKeyVerifier) *ApiKeyAuthenticator {
+	return &ApiKeyAuthenticator{Verifier: verifier}
+}
+
+func (authenticator *ApiKeyAuthenticator) Authenticate(request *http.Request) (Identity, error) {
+	key := request.Header.Get(ApiKeyHeader)
+	if key == "" {
+		return Identity{}, ErrNoCredentials
+	}
+
+	return authenticator.Verifier.VerifyApiKey(request.Context(), key)
+}
+
+// StaticKeyAuthenticator accepts the single key from auth.api_key, which
+// predates database keys and grants every scope. Other keys are left to the
+// authenticators after it.
+type StaticKeyAuthenticator struct {
+	hash [sha256.Size]byte
+}
+
+func NewStaticKeyAuthenticator(key string) *StaticKeyAuthenticator {
+	return &StaticKeyAuthenticator{hash: sha256.Sum256([]byte(key))}
+}
+
+func (authenticator *StaticKeyAuthenticator) Authenticate(request *http.Request) (Identity, error) {
+	key := request.Header.Get(ApiKeyHeader)
+	if key == "" {
+		return Identity{}, ErrNoCredentials
+	}
+
+	// Comparing fixed-size hashes keeps the time independent of the key length.
+	hash := sha256.Sum256([]byte(key))
+	if subtle.ConstantTimeCompare(hash[:], authenticator.hash[:]) != 1 {
+		return Identity{}, ErrNoCredentials
+	}
+
+	return Identity{Type: "static_key", Subject: "static", Name: "auth.api_key", Scopes: Scopes}, nil
+}
@@ -0,0 +1,29 @@
+package auth
+
+import "net/http"
+
+// ClientCertAuthenticator accepts client certificates verified during the
+// TLS handshake. The common name of the subject is looked up in
+// auth.client_certs; certificates it does not list are left to the
+// authenticators after it.
+type ClientCertAuthenticator struct {
+	Scopes map[string][]string
+}
+
+func NewClientCertAuthenticator(scopes map[string][]string) *ClientCertAuthenticator {
+	return &ClientCertAuthenticator{Scopes: scopes}
+}
+
+func (authenticator *ClientCertAuthenticator) Authenticate(request *http.Request) (Identity, error) {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 || len(request.TLS.VerifiedChains[0]) == 0 {
+		return Identity{}, ErrNoCredentials
+	}
+
+	subject := request.TLS.VerifiedChains[0][0].Subject.CommonName
+	scopes, ok := authenticator.Scopes[subject]
+	if subject == "" || !ok {
+		return Identity{}, ErrNoCredentials
+	}
+
+	return Identity{Type: "client_cert", Subject: subject, Name: subject, Scopes: scopes}, nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import "context"
+
+const (
+	ScopeCategoriesRead   = "categories:read"
+	ScopeCategoriesWrite  = "categories:write"
+	ScopeCategoriesDelete = "categories:delete"
+	ScopeAdmin            = "admin"
+)
+
+// Scopes lists every scope a credential can be granted.
+var Scopes = []string{ScopeCategoriesRead, ScopeCategoriesWrite, ScopeCategoriesDelete, ScopeAdmin}
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	// Type names the authenticator, e.g. "api_key".
+	Type    string
+	Subject string
+	Name    string
+	Scopes  []string
+	// Session is the prefix of the user login the credential belongs to,
+	// empty for API keys and for tokens issued by other services.
+	Session string
+	// Claims holds the verified token claims of a JWT caller.
+	Claims map[string]interface{}
+}
+
+func (identity Identity) HasScope(scope string) bool {
+	for _, granted := range identity.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type contextKey struct{}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// IdentityFromContext reports false for anonymous requests to public paths
+// and for work started outside of a request.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+
+	return identity, ok
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"golang-restful-api/config"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// JWTAuthenticator accepts "Authorization: Bearer" tokens issued by other
+// services or by JWTIssuer on login. The token's claims are kept on the
+// identity.
+type JWTAuthenticator struct {
+	Secret []byte
+	Keys   map[string]crypto.PublicKey
+	// Sessions, when set, rejects tokens whose "sid" claim names a session
+	// that has been logged out and takes their scopes from the user.
+	Sessions SessionVerifier
+	parser   *jwt.Parser
+}
+
+func NewJWTAuthenticator(jwtConfig config.JWTConfig) (*JWTAuthenticator, error) {
+	authenticator := &JWTAuthenticator{Secret: []byte(jwtConfig.Secret)}
+
+	var methods []string
+	if jwtConfig.Secret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if jwtConfig.JWKSFile != "" {
+		keys, err := LoadJWKS(jwtConfig.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.Keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(jwtConfig.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if jwtConfig.Issuer != "" {
+		options = append(options, jwt.WithIssuer(jwtConfig.Issuer))
+	}
+	if jwtConfig.Audience != "" {
+		options = append(options, jwt.WithAudience(jwtConfig.Audience))
+	}
+	authenticator.parser = jwt.NewParser(options...)
+
+	return authenticator, nil
+}
+
+func (authenticator *JWTAuthenticator) Authenticate(request *http.Request) (Identity, error) {
+	scheme, token, ok := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Identity{}, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := authenticator.parser.ParseWithClaims(strings.TrimSpace(token), claims, authenticator.key)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return Identity{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	name, _ := claims["name"].(string)
+	if name == "" {
+		name = subject
+	}
+
+	scopes := claimScopes(claims)
+	session, _ := claims["sid"].(string)
+	if session != "" && authenticator.Sessions != nil {
+		// The scopes in a login token are those at login, the user's may
+		// have changed since.
+		user, err := authenticator.Sessions.CheckSession(request.Context(), session)
+		if err != nil {
+			return Identity{}, err
+		}
+		scopes = user.Scopes
+	}
+
+	return Identity{
+		Type:    "jwt",
+		Subject: subject,
+		Name:    name,
+		Scopes:  scopes,
+		Session: session,
+		Claims:  claims,
+	}, nil
+}
+
+func (authenticator *JWTAuthenticator) Challenge() string {
+	return `Bearer realm="api"`
+}
+
+func (authenticator *JWTAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return authenticator.Secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := authenticator.Keys[kid]
+	if !ok && kid == "" && len(authenticator.Keys) == 1 {
+		// A set with a single key does not need key IDs.
+		for _, only := range authenticator.Keys {
+			key, ok = only, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); ok {
+			return key, nil
+		}
+	case *ecdsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodECDSA); ok {
+			return key, nil
+		}
+	}
+
+	return nil, errors.New("key " + kid + " does not match the signing method")
+}
+
+// JWTIssuer signs login tokens with the HS256 secret of auth.jwt, so that
+// JWTAuthenticator accepts them like any other token.
+type JWTIssuer struct {
+	Secret   []byte
+	Issuer   string
+	Audience string
+}
+
+func NewJWTIssuer(jwtConfig config.JWTConfig) *JWTIssuer {
+	return &JWTIssuer{Secret: []byte(jwtConfig.Secret), Issuer: jwtConfig.Issuer, Audience: jwtConfig.Audience}
+}
+
+func (issuer *JWTIssuer) Issue(identity Identity, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":   identity.Subject,
+		"name":  identity.Name,
+		"scope": strings.Join(identity.Scopes, " "),
+		"sid":   identity.Session,
+		"iat":   jwt.NewNumericDate(time.Now()),
+		"exp":   jwt.NewNumericDate(expiresAt),
+	}
+	if issuer.Issuer != "" {
+		claims["iss"] = issuer.Issuer
+	}
+	if issuer.Audience != "" {
+		claims["aud"] = issuer.Audience
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(issuer.Secret)
+}
+
+// claimScopes reads the OAuth 2.0 "scope" claim, a space-separated string,
+// falling back to "scp", which some issuers send as a list.
+func claimScopes(claims jwt.MapClaims) []string {
+	for _, name := range []string{"scope", "scp"} {
+		switch value := claims[name].(type) {
+		case string:
+			return strings.Fields(value)
+		case []interface{}:
+			var scopes []string
+			for _, scope := range value {
+				if scope, ok := scope.(string); ok {
+					scopes = append(scopes, scope)
+				}
+			}
+			return scopes
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"golang-restful-api/exception"
+	"strings"
+)
+
+// Policy maps "METHOD /route/pattern" to the scopes a caller needs, all of
+// them. A route with no scopes only needs to pass authentication; a route
+// missing from the policy is denied.
+type Policy map[string][]string
+
+func (policy Policy) Declares(method string, pattern string) bool {
+	_, ok := policy[method+" "+pattern]
+
+	return ok
+}
+
+// Authorize returns a ForbiddenError naming the missing scopes.
+func (policy Policy) Authorize(ctx context.Context, method string, pattern string) error {
+	scopes, ok := policy[method+" "+pattern]
+	if !ok {
+		return exception.NewForbiddenError("no authorization policy for " + method + " " + pattern)
+	}
+
+	return RequireScopes(ctx, scopes...)
+}
+
+// RequireScopes returns a ForbiddenError naming the scopes the caller of
+// ctx lacks, for checks that depend on more than the route.
+func RequireScopes(ctx context.Context, scopes ...string) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	identity, _ := IdentityFromContext(ctx)
+
+	var missing []string
+	for _, scope := range scopes {
+		if !identity.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return exception.NewForbiddenError("missing scope " + strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SessionVerifier resolves the token of a user login to its identity.
+type SessionVerifier interface {
+	VerifySession(ctx context.Context, token string) (Identity, error)
+	// CheckSession reports ErrInvalidCredentials once the session named by
+	// the "sid" claim of a JWT has been logged out or has expired, and
+	// otherwise the identity of its user as it is now.
+	CheckSession(ctx context.Context, sessionId string) (Identity, error)
+}
+
+// TokenIssuer signs the token handed out on login when sessions are issued
+// as JWTs rather than as opaque tokens.
+type TokenIssuer interface {
+	Issue(identity Identity, expiresAt time.Time) (string, error)
+}
+
+// SessionAuthenticator accepts "Authorization: Bearer" session tokens,
+// "<prefix>.<secret>". Bearer tokens of any other shape, such as JWTs, are
+// left to the authenticators after it.
+type SessionAuthenticator struct {
+	Verifier SessionVerifier
+}
+
+func NewSessionAuthenticator(verifier SessionVerifier) *SessionAuthenticator {
+	return &SessionAuthenticator{Verifier: verifier}
+}
+
+func (authenticator *SessionAuthenticator) Authenticate(request *http.Request) (Identity, error) {
+	scheme, token, ok := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Identity{}, ErrNoCredentials
+	}
+
+	token = strings.TrimSpace(token)
+	if strings.Count(token, ".") != 1 {
+		return Identity{}, ErrNoCredentials
+	}
+
+	return authenticator.Verifier.VerifySession(request.Context(), token)
+}
+
+func (authenticator *SessionAuthenticator) Challenge() string {
+	return `Bearer realm="api"`
+}
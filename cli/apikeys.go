@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"golang-restful-api/model/web"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func apiKeysCommand() *Command {
+	return &Command{
+		Name:    "apikeys",
+		Usage:   "apikeys create | list | rotate | revoke | generate",
+		Summary: "manage API keys",
+		Subcommands: []*Command{
+			{
+				Name:    "create",
+				Usage:   "apikeys create -name name -owner owner -scopes scope,... [-expires duration]",
+				Summary: "issue a key and print its secret once",
+				Run:     createApiKey,
+			},
+			{
+				Name:    "list",
+				Usage:   "apikeys list",
+				Summary: "list issued keys without their secrets",
+				Run:     listApiKeys,
+			},
+			{
+				Name:    "rotate",
+				Usage:   "apikeys rotate <id>",
+				Summary: "replace the secret of a key and print it once",
+				Run:     rotateApiKey,
+			},
+			{
+				Name:    "revoke",
+				Usage:   "apikeys revoke <id>",
+				Summary: "stop accepting a key",
+				Run:     revokeApiKey,
+			},
+			{
+				Name:    "generate",
+				Usage:   "apikeys generate",
+				Summary: "print a random key suitable for auth.api_key",
+				Run:     generateApiKey,
+			},
+		},
+	}
+}
+
+func createApiKey(ctx context.Context, env *Env, args []string) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	name := flags.String("name", "", "what the key is used for")
+	owner := flags.String("owner", "", "who is responsible for the key")
+	scopes := flags.String("scopes", "", "comma-separated scopes")
+	expires := flags.Duration("expires", 0, "lifetime of the key, 0 for none")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return UsageError{Message: err.Error()}
+	}
+	if flags.NArg() > 0 {
+		return UsageError{Message: fmt.Sprintf("unexpected argument %q", flags.Arg(0))}
+	}
+
+	request := web.ApiKeyCreateRequest{Name: *name, Owner: *owner}
+	for _, scope := range strings.Split(*scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			request.Scopes = append(request.Scopes, scope)
+		}
+	}
+	if *expires > 0 {
+		expiresAt := time.Now().Add(*expires)
+		request.ExpiresAt = &expiresAt
+	}
+
+	apiKey, err := env.ApiKeyService().Create(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	printIssuedApiKey(env, apiKey)
+	return nil
+}
+
+func listApiKeys(ctx context.Context, env *Env, args []string) error {
+	if len(args) > 0 {
+		return UsageError{Message: "list takes no arguments"}
+	}
+
+	apiKeys, err := env.ApiKeyService().FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	tab := tabwriter.NewWriter(env.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tab, "ID\tNAME\tOWNER\tPREFIX\tSCOPES\tEXPIRES\tLAST USED\tSTATUS")
+	for _, apiKey := range apiKeys {
+		status := "active"
+		if apiKey.Revoked {
+			status = "revoked"
+		} else if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+			status = "expired"
+		}
+
+		fmt.Fprintf(tab, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", apiKey.Id, apiKey.Name, apiKey.Owner, apiKey.Prefix,
+			strings.Join(apiKey.Scopes, ","), formatTime(apiKey.ExpiresAt), formatTime(apiKey.LastUsedAt), status)
+	}
+
+	return tab.Flush()
+}
+
+func rotateApiKey(ctx context.Context, env *Env, args []string) error {
+	apiKeyId, err := parseApiKeyId(args)
+	if err != nil {
+		return err
+	}
+
+	apiKey, err := env.ApiKeyService().Rotate(ctx, apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	printIssuedApiKey(env, apiKey)
+	return nil
+}
+
+func revokeApiKey(ctx context.Context, env *Env, args []string) error {
+	apiKeyId, err := parseApiKeyId(args)
+	if err != nil {
+		return err
+	}
+
+	err = env.ApiKeyService().Revoke(ctx, apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "revoked api key %d\n", apiKeyId)
+	return nil
+}
+
+func generateApiKey(ctx context.Context, env *Env, args []string) error {
+	if len(args) > 0 {
+		return UsageError{Message: "generate takes no arguments"}
+	}
+
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(env.Stdout, base64.RawURLEncoding.EncodeToString(secret))
+	return nil
+}
+
+func parseApiKeyId(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, UsageError{Message: "expected exactly one api key id"}
+	}
+
+	apiKeyId, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, UsageError{Message: fmt.Sprintf("invalid api key id %q", args[0])}
+	}
+
+	return apiKeyId, nil
+}
+
+// printIssuedApiKey writes only the key to stdout so that scripts can
+// capture it; the description goes to stderr.
+func printIssuedApiKey(env *Env, apiKey web.ApiKeyIssuedResponse) {
+	fmt.Fprintf(env.Stderr, "api key %d (%s) for %s with scopes %s; the key is shown only once:\n",
+		apiKey.Id, apiKey.Name, apiKey.Owner, strings.Join(apiKey.Scopes, ","))
+	fmt.Fprintln(env.Stdout, apiKey.Key)
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return "-"
+	}
+
+	return value.Format(time.RFC3339)
+}
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"golang-restful-api/model/web"
+	"golang-restful-api/service"
+	"io"
+	"os"
+	"strings"
+)
+
+func categoriesCommand() *Command {
+	return &Command{
+		Name:    "categories",
+		Usage:   "categories export | import | reindex",
+		Summary: "export, bulk import or reindex categories",
+		Subcommands: []*Command{
+			{
+				Name:    "export",
+				Usage:   "categories export [-format json|csv] [-output file]",
+				Summary: "write all categories to stdout or a file",
+				Run:     exportCategories,
+			},
+			{
+				Name:    "import",
+				Usage:   "categories import [-format json|csv] [-input file]",
+				Summary: "create categories from stdin or a file",
+				Run:     importCategories,
+			},
+			{
+				Name:    "reindex",
+				Usage:   "categories reindex",
+				Summary: "reapply the name uniqueness rule and give old categories slugs",
+				Run:     reindexCategories,
+			},
+		},
+	}
+}
+
+// categoryRecord is a category as exported and imported. Ids do not survive
+// an import into another database, so a child names its parent by slug and
+// comes after it.
+type categoryRecord struct {
+	Name   string `json:"name"`
+	Slug   string `json:"slug,omitempty"`
+	Parent string `json:"parent,omitempty"`
+	// ParentId is only read to reject exports of older versions.
+	ParentId *int `json:"parent_id,omitempty"`
+}
+
+type transferFlags struct {
+	format string
+	path   string
+}
+
+func parseTransferFlags(name string, pathFlag string, args []string) (transferFlags, error) {
+	result := transferFlags{}
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&result.format, "format", "json", "json or csv")
+	flags.StringVar(&result.path, pathFlag, "-", "file path, - for standard streams")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return result, UsageError{Message: err.Error()}
+	}
+	if flags.NArg() > 0 {
+		return result, UsageError{Message: fmt.Sprintf("unexpected argument %q", flags.Arg(0))}
+	}
+	if result.format != "json" && result.format != "csv" {
+		return result, UsageError{Message: fmt.Sprintf("unknown format %q", result.format)}
+	}
+
+	return result, nil
+}
+
+func exportCategories(ctx context.Context, env *Env, args []string) error {
+	transfer, err := parseTransferFlags("export", "output", args)
+	if err != nil {
+		return err
+	}
+
+	trees, err := env.CategoryService().FindTree(ctx)
+	if err != nil {
+		return err
+	}
+	records, err := flattenCategoryTrees("", trees, []categoryRecord{})
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	writer := env.Stdout
+	if transfer.path != "-" {
+		file, err := os.Create(transfer.path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	if transfer.format == "json" {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	_ = csvWriter.Write([]string{"name", "slug", "parent"})
+	for _, record := range records {
+		_ = csvWriter.Write([]string{record.Name, record.Slug, record.Parent})
+	}
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// flattenCategoryTrees lists every category after its parent.
+func flattenCategoryTrees(parent string, trees []web.CategoryTreeResponse, records []categoryRecord) ([]categoryRecord, error) {
+	for _, tree := range trees {
+		if tree.Slug == "" && len(tree.Children) > 0 {
+			return nil, fmt.Errorf("category %d has no slug for its children to refer to, run categories reindex first", tree.Id)
+		}
+
+		var err error
+		records = append(records, categoryRecord{Name: tree.Name, Slug: tree.Slug, Parent: parent})
+		records, err = flattenCategoryTrees(tree.Slug, tree.Children, records)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
+
+// importCategories validates every record with the same rules as
+// CategoryService.Create before creating any of them. A parent must be an
+// earlier record; its slug is mapped to the id it gets in this database.
+func importCategories(ctx context.Context, env *Env, args []string) error {
+	transfer, err := parseTransferFlags("import", "input", args)
+	if err != nil {
+		return err
+	}
+
+	reader := env.Stdin
+	if transfer.path != "-" {
+		file, err := os.Open(transfer.path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var records []categoryRecord
+	if transfer.format == "json" {
+		err = json.NewDecoder(reader).Decode(&records)
+	} else {
+		records, err = readCategoriesCsv(reader)
+	}
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	var invalid []string
+	seen := map[string]int{}
+	slugs := map[string]int{}
+	for i, record := range records {
+		// Slugs are compared as the service will store them.
+		record.Slug = service.Slugify(record.Slug)
+		record.Parent = service.Slugify(record.Parent)
+		records[i] = record
+
+		if record.ParentId != nil {
+			invalid = append(invalid, fmt.Sprintf("record %d: parent_id cannot be imported, name the slug of the parent in parent", i+1))
+			continue
+		}
+		if first, ok := slugs[record.Slug]; ok && record.Slug != "" {
+			invalid = append(invalid, fmt.Sprintf("record %d: slug %q duplicates record %d", i+1, record.Slug, first))
+			continue
+		}
+		if _, ok := slugs[record.Parent]; !ok && record.Parent != "" {
+			invalid = append(invalid, fmt.Sprintf("record %d: parent %q is not the slug of an earlier record", i+1, record.Parent))
+			continue
+		}
+		slugs[record.Slug] = i + 1
+
+		request := web.CategoryCreateRequest{Name: record.Name, Slug: record.Slug}
+		err := env.Validate().Struct(request)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("record %d: %s", i+1, err))
+			continue
+		}
+
+		nameKey := env.NameRule().Key(request.Name)
+		if first, ok := seen[nameKey]; ok && nameKey != "" {
+			invalid = append(invalid, fmt.Sprintf("record %d: name %q duplicates record %d", i+1, request.Name, first))
+			continue
+		}
+		seen[nameKey] = i + 1
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("import: %d invalid records, nothing imported\n%s", len(invalid), strings.Join(invalid, "\n"))
+	}
+
+	categoryService := env.CategoryService()
+	ids := map[string]int{}
+	for i, record := range records {
+		request := web.CategoryCreateRequest{Name: record.Name, Slug: record.Slug, ParentId: ids[record.Parent]}
+		category, err := categoryService.Create(ctx, request)
+		if err != nil {
+			return fmt.Errorf("import: record %d: %w (%d records imported)", i+1, err, i)
+		}
+		if record.Slug != "" {
+			ids[record.Slug] = category.Id
+		}
+	}
+
+	fmt.Fprintf(env.Stdout, "imported %d categories\n", len(records))
+	return nil
+}
+
+func reindexCategories(ctx context.Context, env *Env, args []string) error {
+	if len(args) > 0 {
+		return UsageError{Message: "reindex takes no arguments"}
+	}
+
+	updated, err := env.CategoryService().RebuildNameKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("reindex: %w", err)
+	}
+
+	generated, err := env.CategoryService().GenerateMissingSlugs(ctx)
+	if err != nil {
+		return fmt.Errorf("reindex: %w", err)
+	}
+
+	fmt.Fprintf(env.Stdout, "reindexed %d categories\n", updated)
+	fmt.Fprintf(env.Stdout, "generated %d slugs\n", generated)
+	return nil
+}
+
+// readCategoriesCsv reads the name column and, when present, the slug and
+// parent columns.
+func readCategoriesCsv(reader io.Reader) ([]categoryRecord, error) {
+	rows, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, header := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	nameColumn, ok := columns["name"]
+	if !ok {
+		return nil, errors.New("csv header has no name column")
+	}
+	if _, ok := columns["parent_id"]; ok {
+		return nil, errors.New("csv column parent_id cannot be imported, name the slug of the parent in a parent column")
+	}
+	cell := func(row []string, name string) string {
+		column, ok := columns[name]
+		if !ok {
+			return ""
+		}
+		return row[column]
+	}
+
+	var records []categoryRecord
+	for _, row := range rows[1:] {
+		records = append(records, categoryRecord{Name: row[nameColumn], Slug: cell(row, "slug"), Parent: cell(row, "parent")})
+	}
+
+	return records, nil
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"golang-restful-api/app"
+	"golang-restful-api/auth"
+	"golang-restful-api/config"
+	"io"
+	"os"
+	"os/signal"
+	"os/user"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+)
+
+const (
+	ExitOK     = 0
+	ExitError  = 1
+	ExitUsage  = 2
+	ExitConfig = 3
+)
+
+type Command struct {
+	Name        string
+	Usage       string
+	Summary     string
+	Subcommands []*Command
+	Run         func(ctx context.Context, env *Env, args []string) error
+}
+
+type UsageError struct {
+	Message string
+}
+
+func (err UsageError) Error() string {
+	return err.Message
+}
+
+func Commands() []*Command {
+	return []*Command{
+		serveCommand(),
+		migrateCommand(),
+		seedCommand(),
+		categoriesCommand(),
+		apiKeysCommand(),
+		usersCommand(),
+	}
+}
+
+// Run executes the command line and returns the process exit code. Without a
+// command the server is started, as the binary did before it had subcommands.
+func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	program := "golang-restful-api"
+	commands := Commands()
+
+	flags := flag.NewFlagSet(program, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		printUsage(stderr, program, nil, commands)
+		fmt.Fprintln(stderr, "\nGlobal flags:")
+		flags.PrintDefaults()
+	}
+	loader := config.NewLoader(flags)
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	command, path, args := find(commands, args)
+	if command == nil || command.Run == nil {
+		if command == nil {
+			fmt.Fprintf(stderr, "unknown command %q\n\n", strings.Join(path, " "))
+			printUsage(stderr, program, nil, commands)
+		} else {
+			printUsage(stderr, program, path, command.Subcommands)
+		}
+		fmt.Fprintf(stderr, "\nRun '%s -h' to list the global flags.\n", program)
+		return ExitUsage
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return ExitConfig
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Once the first signal starts the shutdown, a second one kills the
+	// process instead of waiting for the drain.
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	ctx = auth.WithIdentity(ctx, cliIdentity())
+
+	env := &Env{
+		Config:   cfg,
+		Stdin:    stdin,
+		Stdout:   stdout,
+		Stderr:   stderr,
+		Shutdown: app.NewShutdown(cfg.Server.ShutdownTimeout),
+	}
+
+	err = execute(ctx, env, command, args)
+	err = errors.Join(err, env.Shutdown.Run())
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+
+		var usageError UsageError
+		if errors.As(err, &usageError) {
+			fmt.Fprintf(stderr, "usage: %s %s\n", program, command.Usage)
+			return ExitUsage
+		}
+		return ExitError
+	}
+
+	return ExitOK
+}
+
+// cliIdentity names the operating system user as the actor of changes made
+// from the command line, e.g. in the audit log.
+func cliIdentity() auth.Identity {
+	name := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+
+	return auth.Identity{Type: "cli", Subject: name, Name: name}
+}
+
+// execute reports a panic as a command failure instead of crashing with a
+// stack trace on the user's terminal.
+func execute(ctx context.Context, env *Env, command *Command, args []string) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if recoveredErr, ok := recovered.(error); ok {
+				err = recoveredErr
+			} else {
+				err = fmt.Errorf("%v", recovered)
+			}
+		}
+	}()
+
+	return command.Run(ctx, env, args)
+}
+
+func find(commands []*Command, args []string) (*Command, []string, []string) {
+	var path []string
+	var command *Command
+
+	for len(args) > 0 {
+		var next *Command
+		for _, candidate := range commands {
+			if candidate.Name == args[0] {
+				next = candidate
+			}
+		}
+
+		if next == nil {
+			if command == nil {
+				return nil, []string{args[0]}, nil
+			}
+			break
+		}
+
+		command = next
+		path = append(path, args[0])
+		args = args[1:]
+		commands = command.Subcommands
+
+		if len(commands) == 0 {
+			break
+		}
+	}
+
+	return command, path, args
+}
+
+func printUsage(writer io.Writer, program string, path []string, commands []*Command) {
+	prefix := ""
+	if len(path) > 0 {
+		prefix = strings.Join(path, " ") + " "
+	}
+	fmt.Fprintf(writer, "Usage: %s [global flags] %s<command> [arguments]\n\nCommands:\n", program, prefix)
+
+	tab := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	for _, command := range commands {
+		fmt.Fprintf(tab, "  %s\t%s\n", command.Name, command.Summary)
+	}
+	_ = tab.Flush()
+}
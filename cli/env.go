@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"github.com/go-playground/validator/v10"
+	"golang-restful-api/app"
+	"golang-restful-api/auth"
+	"golang-restful-api/config"
+	"golang-restful-api/migration"
+	"golang-restful-api/reporter"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"io"
+)
+
+// Env carries the loaded configuration and lazily builds the dependencies a
+// command needs, so that e.g. "apikeys generate" never opens the database.
+type Env struct {
+	Config   *config.Config
+	Stdin    io.Reader
+	Stdout   io.Writer
+	Stderr   io.Writer
+	Shutdown *app.Shutdown
+
+	dialect  repository.Dialect
+	db       *sql.DB
+	validate *validator.Validate
+	reporter reporter.Reporter
+	users    service.UserService
+}
+
+func (env *Env) Dialect() repository.Dialect {
+	if env.dialect == nil {
+		env.dialect = app.NewDialect(env.Config.Database)
+	}
+
+	return env.dialect
+}
+
+func (env *Env) DB() *sql.DB {
+	if env.db == nil {
+		db := app.NewDB(env.Config.Database, env.Dialect())
+		env.Shutdown.Register("database", func(ctx context.Context) error {
+			return db.Close()
+		})
+		env.db = db
+	}
+
+	return env.db
+}
+
+func (env *Env) Validate() *validator.Validate {
+	if env.validate == nil {
+		env.validate = app.NewValidator()
+	}
+
+	return env.validate
+}
+
+func (env *Env) Reporter() (reporter.Reporter, error) {
+	if env.reporter != nil {
+		return env.reporter, nil
+	}
+
+	cfg := env.Config.Reporter
+	switch cfg.Type {
+	case "file":
+		fileReporter, err := reporter.NewFileReporter(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		env.Shutdown.Register("error reporter", func(ctx context.Context) error {
+			return fileReporter.Close()
+		})
+		env.reporter = fileReporter
+	case "http":
+		env.reporter = reporter.NewHTTPReporter(cfg.URL, cfg.Timeout)
+	default:
+		env.reporter = reporter.NewLogReporter(env.Stderr)
+	}
+
+	return env.reporter, nil
+}
+
+func (env *Env) Migrator() (*migration.Migrator, error) {
+	return migration.NewMigrator(env.DB(), env.Dialect())
+}
+
+func (env *Env) CategoryRepository() repository.CategoryRepository {
+	return repository.NewCategoryRepository(env.Dialect())
+}
+
+func (env *Env) NameRule() service.NameRule {
+	return service.NameRule(env.Config.Category.UniqueNames)
+}
+
+func (env *Env) CategoryService() service.CategoryService {
+	return service.NewCategoryService(env.CategoryRepository(), repository.NewAuditRepository(env.Dialect()), repository.NewSqlDatabase(env.DB()), env.Validate(), env.NameRule(), env.PageSize(), env.Hierarchy())
+}
+
+func (env *Env) PageSize() service.PageSize {
+	return service.PageSize{Default: env.Config.Category.DefaultPageSize, Max: env.Config.Category.MaxPageSize}
+}
+
+func (env *Env) Hierarchy() service.Hierarchy {
+	return service.Hierarchy{MaxDepth: env.Config.Category.MaxDepth, DeletePolicy: service.DeletePolicy(env.Config.Category.DeleteChildren)}
+}
+
+func (env *Env) AuditService() service.AuditService {
+	return service.NewAuditService(repository.NewAuditRepository(env.Dialect()), repository.NewSqlDatabase(env.DB()), env.Validate())
+}
+
+func (env *Env) ApiKeyService() service.ApiKeyService {
+	return service.NewApiKeyService(repository.NewApiKeyRepository(env.Dialect()), repository.NewSqlDatabase(env.DB()), env.Validate())
+}
+
+// UserService is shared so that the server's authenticators and controller
+// see the same service.
+func (env *Env) UserService() service.UserService {
+	if env.users == nil {
+		cfg := env.Config.Auth.Users
+		policy := service.LoginPolicy{
+			SessionTTL:         cfg.SessionTTL,
+			MaxFailedLogins:    cfg.MaxFailedLogins,
+			LockoutDuration:    cfg.LockoutDuration,
+			BcryptCost:         cfg.BcryptCost,
+			Registration:       cfg.Registration,
+			RegistrationScopes: cfg.RegistrationScopes,
+		}
+		if cfg.Tokens == "jwt" {
+			policy.Issuer = auth.NewJWTIssuer(env.Config.Auth.JWT)
+		}
+
+		env.users = service.NewUserService(repository.NewUserRepository(env.Dialect()), repository.NewUserSessionRepository(env.Dialect()),
+			repository.NewSqlDatabase(env.DB()), env.Validate(), policy)
+	}
+
+	return env.users
+}
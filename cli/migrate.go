@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+)
+
+func migrateCommand() *Command {
+	return &Command{
+		Name:    "migrate",
+		Usage:   "migrate up | down [steps] | status | goto <version>",
+		Summary: "apply, revert or inspect schema migrations",
+		Subcommands: []*Command{
+			{Name: "up", Usage: "migrate up", Summary: "apply all pending migrations", Run: migrateUp},
+			{Name: "down", Usage: "migrate down [steps]", Summary: "revert the last migrations (default 1)", Run: migrateDown},
+			{Name: "status", Usage: "migrate status", Summary: "list migrations and when they were applied", Run: migrateStatus},
+			{Name: "goto", Usage: "migrate goto <version>", Summary: "migrate up or down to a version", Run: migrateGoto},
+		},
+	}
+}
+
+func migrateUp(ctx context.Context, env *Env, args []string) error {
+	migrator, err := env.Migrator()
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up(ctx)
+}
+
+func migrateDown(ctx context.Context, env *Env, args []string) error {
+	steps := 1
+	if len(args) > 0 {
+		var err error
+		steps, err = strconv.Atoi(args[0])
+		if err != nil || steps < 1 {
+			return UsageError{Message: fmt.Sprintf("invalid step count %q", args[0])}
+		}
+	}
+
+	migrator, err := env.Migrator()
+	if err != nil {
+		return err
+	}
+
+	return migrator.Down(ctx, steps)
+}
+
+func migrateGoto(ctx context.Context, env *Env, args []string) error {
+	if len(args) != 1 {
+		return UsageError{Message: "goto needs exactly one version"}
+	}
+
+	version, err := strconv.Atoi(args[0])
+	if err != nil {
+		return UsageError{Message: fmt.Sprintf("invalid version %q", args[0])}
+	}
+
+	migrator, err := env.Migrator()
+	if err != nil {
+		return err
+	}
+
+	return migrator.Goto(ctx, version)
+}
+
+func migrateStatus(ctx context.Context, env *Env, args []string) error {
+	migrator, err := env.Migrator()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(env.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = status.AppliedAt
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return writer.Flush()
+}
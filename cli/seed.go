@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/web"
+	"io"
+)
+
+var seedCategories = []string{
+	"Electronics",
+	"Books",
+	"Clothing",
+	"Home & Kitchen",
+	"Sports & Outdoors",
+	"Toys & Games",
+}
+
+func seedCommand() *Command {
+	return &Command{
+		Name:    "seed",
+		Usage:   "seed [-force]",
+		Summary: "create a sample set of categories",
+		Run:     seed,
+	}
+}
+
+func seed(ctx context.Context, env *Env, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	force := flags.Bool("force", false, "seed even if categories already exist")
+
+	err := flags.Parse(args)
+	if err != nil || flags.NArg() > 0 {
+		return UsageError{Message: "seed accepts only -force"}
+	}
+
+	categoryService := env.CategoryService()
+
+	existing, err := categoryService.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(existing) > 0 && !*force {
+		fmt.Fprintln(env.Stdout, "categories already exist, nothing seeded (use -force to seed anyway)")
+		return nil
+	}
+
+	seeded := 0
+	for _, name := range seedCategories {
+		_, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: name})
+		if errors.As(err, &exception.ConflictError{}) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		seeded++
+	}
+
+	fmt.Fprintf(env.Stdout, "seeded %d categories\n", seeded)
+	return nil
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"golang-restful-api/app"
+	"golang-restful-api/auth"
+	"golang-restful-api/controller"
+	"golang-restful-api/exception"
+	"golang-restful-api/health"
+	"golang-restful-api/i18n"
+	"golang-restful-api/middleware"
+	"golang-restful-api/ratelimit"
+	"net"
+	"net/http"
+)
+
+func serveCommand() *Command {
+	return &Command{
+		Name:    "serve",
+		Usage:   "serve",
+		Summary: "start the HTTP server (default)",
+		Run:     serve,
+	}
+}
+
+func serve(ctx context.Context, env *Env, args []string) error {
+	if len(args) > 0 {
+		return UsageError{Message: "serve takes no arguments"}
+	}
+
+	env.Shutdown.Delay = env.Config.Server.ShutdownDelay
+
+	migrator, err := env.Migrator()
+	if err != nil {
+		return err
+	}
+
+	if env.Config.Database.AutoMigrate {
+		err = migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	checks := health.NewHealth(env.Config.Health.CheckTimeout)
+	checks.Register(health.NewDatabaseChecker(env.DB()))
+	checks.Register(health.NewMigrationChecker(migrator))
+	checks.Register(health.NewShutdownChecker(env.Shutdown))
+
+	decoder := controller.NewRequestDecoder(env.Config.Server.MaxBodyBytes, env.Config.Server.StrictJSON)
+	apiKeyService := env.ApiKeyService()
+	userService := env.UserService()
+
+	categoryController := controller.NewCategoryController(env.CategoryService(), decoder)
+	apiKeyController := controller.NewApiKeyController(apiKeyService, decoder)
+	userController := controller.NewUserController(userService, decoder)
+	auditController := controller.NewAuditController(env.AuditService())
+	healthController := controller.NewHealthController(checks)
+
+	router := app.NewRouter(categoryController, apiKeyController, userController, auditController, healthController)
+
+	var authenticators []auth.Authenticator
+	if env.Config.Server.TLS.Enabled && env.Config.Server.TLS.ClientAuth != "none" {
+		authenticators = append(authenticators, auth.NewClientCertAuthenticator(env.Config.Auth.ClientCerts))
+	}
+	if env.Config.Auth.APIKey != "" {
+		authenticators = append(authenticators, auth.NewStaticKeyAuthenticator(env.Config.Auth.APIKey))
+	}
+	authenticators = append(authenticators, auth.NewApiKeyAuthenticator(apiKeyService), auth.NewSessionAuthenticator(userService))
+	if env.Config.Auth.JWT.Enabled {
+		jwtAuthenticator, err := auth.NewJWTAuthenticator(env.Config.Auth.JWT)
+		if err != nil {
+			return err
+		}
+		jwtAuthenticator.Sessions = userService
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	translator, err := i18n.NewTranslator(env.Validate())
+	if err != nil {
+		return err
+	}
+
+	errorReporter, err := env.Reporter()
+	if err != nil {
+		return err
+	}
+
+	var handler http.Handler = middleware.NewLocaleMiddleware(router, translator)
+	var rateLimit func(handler http.Handler) http.Handler
+	if env.Config.RateLimit.Enabled {
+		rules, err := app.NewRateLimitRules(env.Config.RateLimit)
+		if err != nil {
+			return err
+		}
+		store := ratelimit.NewMemoryStore()
+		rateLimit = func(handler http.Handler) http.Handler {
+			return middleware.NewRateLimitMiddleware(handler, store, rules, app.HealthPaths...)
+		}
+		handler = rateLimit(handler)
+	}
+	authMiddleware := middleware.NewAuthMiddleware(handler, authenticators, app.PublicPaths...)
+	if rateLimit != nil {
+		// Rejected credentials count against the client's IP, so that
+		// guessing them is limited too.
+		authMiddleware.Unauthorized = rateLimit(authMiddleware.Unauthorized)
+	}
+	handler = authMiddleware
+	handler = middleware.NewErrorFormatMiddleware(handler, exception.Format(env.Config.Server.ErrorFormat))
+	handler = middleware.NewReporterMiddleware(handler, errorReporter)
+	handler = middleware.NewClientIpMiddleware(handler, env.Config.Server.ClientIPHeader)
+	handler = middleware.NewRequestIdMiddleware(handler)
+
+	server := app.NewServer(env.Config.Server, handler)
+	if env.Config.Server.TLS.Enabled {
+		reloader, err := app.NewTLSReloader(env.Config.Server.TLS)
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = reloader.TLSConfig()
+		go reloader.Watch(ctx, env.Config.Server.TLS.ReloadInterval)
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+
+	return app.Serve(ctx, server, listener, env.Shutdown)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"golang-restful-api/model/web"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func usersCommand() *Command {
+	return &Command{
+		Name:    "users",
+		Usage:   "users create | list | unlock",
+		Summary: "manage user accounts",
+		Subcommands: []*Command{
+			{
+				Name:    "create",
+				Usage:   "users create -username name [-scopes scope,...] < password",
+				Summary: "register a user, reading the password from the first line of stdin",
+				Run:     createUser,
+			},
+			{
+				Name:    "list",
+				Usage:   "users list",
+				Summary: "list users",
+				Run:     listUsers,
+			},
+			{
+				Name:    "unlock",
+				Usage:   "users unlock <id>",
+				Summary: "clear a lockout after failed logins",
+				Run:     unlockUser,
+			},
+		},
+	}
+}
+
+// createUser reads the password from stdin so that it stays out of the
+// shell history and the process list.
+func createUser(ctx context.Context, env *Env, args []string) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	username := flags.String("username", "", "login name")
+	scopes := flags.String("scopes", "", "comma-separated scopes")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return UsageError{Message: err.Error()}
+	}
+	if flags.NArg() > 0 {
+		return UsageError{Message: fmt.Sprintf("unexpected argument %q", flags.Arg(0))}
+	}
+
+	password, err := bufio.NewReader(env.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	request := web.UserCreateRequest{Username: *username, Password: strings.TrimRight(password, "\r\n")}
+	for _, scope := range strings.Split(*scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			request.Scopes = append(request.Scopes, scope)
+		}
+	}
+
+	user, err := env.UserService().Create(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "created user %d (%s) with scopes %s\n", user.Id, user.Username, strings.Join(user.Scopes, ","))
+	return nil
+}
+
+func listUsers(ctx context.Context, env *Env, args []string) error {
+	if len(args) > 0 {
+		return UsageError{Message: "list takes no arguments"}
+	}
+
+	users, err := env.UserService().FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	tab := tabwriter.NewWriter(env.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tab, "ID\tUSERNAME\tSCOPES\tPASSWORD CHANGED\tLOCKED UNTIL")
+	for _, user := range users {
+		fmt.Fprintf(tab, "%d\t%s\t%s\t%s\t%s\n", user.Id, user.Username, strings.Join(user.Scopes, ","),
+			formatTime(&user.PasswordChangedAt), formatTime(user.LockedUntil))
+	}
+
+	return tab.Flush()
+}
+
+func unlockUser(ctx context.Context, env *Env, args []string) error {
+	if len(args) != 1 {
+		return UsageError{Message: "expected exactly one user id"}
+	}
+
+	userId, err := strconv.Atoi(args[0])
+	if err != nil {
+		return UsageError{Message: fmt.Sprintf("invalid user id %q", args[0])}
+	}
+
+	err = env.UserService().Unlock(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "unlocked user %d\n", userId)
+	return nil
+}
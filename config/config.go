@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Health    HealthConfig    `yaml:"health"`
+	Reporter  ReporterConfig  `yaml:"reporter"`
+	Category  CategoryConfig  `yaml:"category"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+type ServerConfig struct {
+	Address           string        `yaml:"address" validate:"required,hostname_port"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" validate:"min=0"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" validate:"min=0"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" validate:"min=0"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" validate:"min=0"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" validate:"gt=0"`
+	ShutdownDelay     time.Duration `yaml:"shutdown_delay" validate:"min=0"`
+	ErrorFormat       string        `yaml:"error_format" validate:"oneof=envelope problem"`
+	MaxBodyBytes      int64         `yaml:"max_body_bytes" validate:"gt=0"`
+	StrictJSON        bool          `yaml:"strict_json"`
+	// ClientIPHeader names a header set by a trusted proxy, such as
+	// X-Forwarded-For, whose last address is the client. Empty uses the
+	// connection's address.
+	ClientIPHeader string    `yaml:"client_ip_header"`
+	TLS            TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig serves HTTPS. The files are checked every ReloadInterval and
+// reloaded when they change, so certificates can be rotated in place.
+// ClientAuth "optional" or "require" verifies client certificates against
+// ClientCAFile.
+type TLSConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	CertFile       string        `yaml:"cert_file" validate:"required_if=Enabled true"`
+	KeyFile        string        `yaml:"key_file" validate:"required_if=Enabled true"`
+	MinVersion     string        `yaml:"min_version" validate:"oneof=1.2 1.3"`
+	CipherSuites   []string      `yaml:"cipher_suites"`
+	ReloadInterval time.Duration `yaml:"reload_interval" validate:"gt=0"`
+	ClientAuth     string        `yaml:"client_auth" validate:"oneof=none optional require"`
+	ClientCAFile   string        `yaml:"client_ca_file"`
+}
+
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver" validate:"required,oneof=mysql postgres sqlite"`
+	DSN             string        `yaml:"dsn" validate:"required"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" validate:"min=0"`
+	MaxOpenConns    int           `yaml:"max_open_conns" validate:"min=0"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" validate:"min=0"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" validate:"min=0"`
+	AutoMigrate     bool          `yaml:"auto_migrate"`
+}
+
+type AuthConfig struct {
+	// APIKey is a single key granting every scope, kept for deployments that
+	// predate database keys. Empty disables it.
+	APIKey string      `yaml:"api_key"`
+	JWT    JWTConfig   `yaml:"jwt"`
+	Users  UsersConfig `yaml:"users"`
+	// ClientCerts grants scopes to verified client certificates by the
+	// common name of their subject. Certificates not listed here are not
+	// accepted as credentials.
+	ClientCerts map[string][]string `yaml:"client_certs" validate:"dive,dive,oneof=categories:read categories:write categories:delete admin"`
+}
+
+// JWTConfig enables "Authorization: Bearer" tokens signed with HS256 using
+// Secret, or with RS256/ES256 using the keys in JWKSFile.
+type JWTConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Secret   string        `yaml:"secret"`
+	JWKSFile string        `yaml:"jwks_file"`
+	Issuer   string        `yaml:"issuer"`
+	Audience string        `yaml:"audience"`
+	Leeway   time.Duration `yaml:"leeway" validate:"min=0"`
+}
+
+// UsersConfig controls password logins. Tokens is "session" for opaque
+// tokens or "jwt" for tokens signed with auth.jwt.secret; either kind can be
+// revoked by logging out.
+type UsersConfig struct {
+	Tokens          string        `yaml:"tokens" validate:"oneof=session jwt"`
+	SessionTTL      time.Duration `yaml:"session_ttl" validate:"gt=0"`
+	MaxFailedLogins int           `yaml:"max_failed_logins" validate:"gt=0"`
+	LockoutDuration time.Duration `yaml:"lockout_duration" validate:"gt=0"`
+	BcryptCost      int           `yaml:"bcrypt_cost" validate:"min=4,max=31"`
+	// Registration opens POST /api/auth/register to anyone, granting
+	// RegistrationScopes. Otherwise admins create accounts.
+	Registration       bool     `yaml:"registration"`
+	RegistrationScopes []string `yaml:"registration_scopes" validate:"dive,oneof=categories:read categories:write categories:delete"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" validate:"gt=0"`
+}
+
+// ReporterConfig selects where internal errors are sent: the server log, a
+// JSON lines file, or an HTTP collector.
+type ReporterConfig struct {
+	Type    string        `yaml:"type" validate:"oneof=log file http"`
+	File    string        `yaml:"file" validate:"required_if=Type file"`
+	URL     string        `yaml:"url" validate:"required_if=Type http,omitempty,url"`
+	Timeout time.Duration `yaml:"timeout" validate:"gt=0"`
+}
+
+// RateLimitConfig limits each client, identified by its credentials or its
+// IP address, to Default or to its entry in Keys ("api_key:12",
+// "session:3", "ip:10.0.0.1"). Routes, keyed "GET /api/categories", add a
+// separate limit per client on single routes.
+type RateLimitConfig struct {
+	Enabled bool                   `yaml:"enabled"`
+	Store   string                 `yaml:"store" validate:"oneof=memory"`
+	Default LimitConfig            `yaml:"default"`
+	Keys    map[string]LimitConfig `yaml:"keys" validate:"dive"`
+	Routes  map[string]LimitConfig `yaml:"routes" validate:"dive"`
+}
+
+// LimitConfig allows Requests per Per, in bursts of up to Burst (default
+// Requests).
+type LimitConfig struct {
+	Requests int           `yaml:"requests" validate:"gt=0"`
+	Per      time.Duration `yaml:"per" validate:"gt=0"`
+	Burst    int           `yaml:"burst" validate:"min=0"`
+}
+
+type CategoryConfig struct {
+	// UniqueNames is off, exact, or normalized (case-insensitive, NFKC,
+	// whitespace-trimmed).
+	UniqueNames string `yaml:"unique_names" validate:"oneof=off exact normalized"`
+	// DefaultPageSize is the page size of GET /api/categories without a
+	// limit; larger limits than MaxPageSize are rejected.
+	DefaultPageSize int `yaml:"default_page_size" validate:"gt=0,ltefield=MaxPageSize"`
+	MaxPageSize     int `yaml:"max_page_size" validate:"gt=0"`
+	// MaxDepth is how many levels deep categories may be nested, root
+	// categories being level 1.
+	MaxDepth int `yaml:"max_depth" validate:"gt=0"`
+	// DeleteChildren is what deleting a category with children does unless
+	// the request says: reject, cascade or reparent.
+	DeleteChildren string `yaml:"delete_children" validate:"oneof=reject cascade reparent"`
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Address:           "localhost:3000",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			ErrorFormat:       "envelope",
+			MaxBodyBytes:      1 << 20,
+			TLS: TLSConfig{
+				MinVersion:     "1.2",
+				ReloadInterval: 10 * time.Second,
+				ClientAuth:     "none",
+			},
+		},
+		Database: DatabaseConfig{
+			Driver:          "mysql",
+			DSN:             "devtest:root@tcp(localhost:3306)/dev",
+			MaxIdleConns:    5,
+			MaxOpenConns:    50,
+			ConnMaxIdleTime: 10 * time.Minute,
+			ConnMaxLifetime: 60 * time.Minute,
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				Leeway: 30 * time.Second,
+			},
+			Users: UsersConfig{
+				Tokens:             "session",
+				SessionTTL:         12 * time.Hour,
+				MaxFailedLogins:    5,
+				LockoutDuration:    15 * time.Minute,
+				BcryptCost:         12,
+				RegistrationScopes: []string{"categories:read"},
+			},
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+		},
+		Reporter: ReporterConfig{
+			Type:    "log",
+			Timeout: 2 * time.Second,
+		},
+		Category: CategoryConfig{
+			UniqueNames:     "normalized",
+			DefaultPageSize: 50,
+			MaxPageSize:     500,
+			MaxDepth:        8,
+			DeleteChildren:  "reject",
+		},
+		RateLimit: RateLimitConfig{
+			Store: "memory",
+			Default: LimitConfig{
+				Requests: 60,
+				Per:      time.Minute,
+			},
+		},
+	}
+}
+
+func (config *Config) Validate() error {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+	})
+
+	err := validate.Struct(config)
+	if err != nil {
+		return fmt.Errorf("config: invalid configuration: %w", err)
+	}
+
+	jwt := config.Auth.JWT
+	if jwt.Enabled && jwt.Secret == "" && jwt.JWKSFile == "" {
+		return fmt.Errorf("config: invalid configuration: auth.jwt needs a secret or a jwks_file")
+	}
+	tls := config.Server.TLS
+	if tls.Enabled && tls.ClientAuth != "none" && tls.ClientCAFile == "" {
+		return fmt.Errorf("config: invalid configuration: server.tls.client_auth %s needs a client_ca_file", tls.ClientAuth)
+	}
+	if config.Auth.Users.Tokens == "jwt" && (!jwt.Enabled || jwt.Secret == "") {
+		return fmt.Errorf("config: invalid configuration: auth.users.tokens jwt needs auth.jwt enabled with a secret")
+	}
+
+	return nil
+}
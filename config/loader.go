@@ -0,0 +1,186 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const EnvPrefix = "APP"
+
+// Loader builds a Config from defaults, a YAML or JSON file, APP_* environment
+// variables and command-line flags, each source overriding the previous one.
+type Loader struct {
+	File      string
+	overrides map[string]string
+}
+
+func NewLoader(flags *flag.FlagSet) *Loader {
+	loader := &Loader{overrides: map[string]string{}}
+
+	flags.StringVar(&loader.File, "config", "", "path to a YAML or JSON config file (env "+EnvPrefix+"_CONFIG)")
+
+	_ = walk(reflect.ValueOf(Default()).Elem(), "", func(key string, value reflect.Value) error {
+		flags.Func(key, fmt.Sprintf("override %s (env %s)", key, EnvName(key)), func(raw string) error {
+			err := set(reflect.New(value.Type()).Elem(), raw)
+			if err != nil {
+				return err
+			}
+
+			loader.overrides[key] = raw
+			return nil
+		})
+		return nil
+	})
+
+	return loader
+}
+
+func (loader *Loader) Load() (*Config, error) {
+	config := Default()
+
+	file := loader.File
+	if file == "" {
+		file = os.Getenv(EnvPrefix + "_CONFIG")
+	}
+
+	if file != "" {
+		err := loadFile(file, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := walk(reflect.ValueOf(config).Elem(), "", func(key string, value reflect.Value) error {
+		if raw, ok := os.LookupEnv(EnvName(key)); ok {
+			err := set(value, raw)
+			if err != nil {
+				return fmt.Errorf("config: environment variable %s: %w", EnvName(key), err)
+			}
+		}
+
+		if raw, ok := loader.overrides[key]; ok {
+			err := set(value, raw)
+			if err != nil {
+				return fmt.Errorf("config: flag -%s: %w", key, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func loadFile(path string, config *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return fmt.Errorf("config: unsupported config file extension %q", filepath.Ext(path))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	defer file.Close()
+
+	// JSON is a subset of YAML, so a single decoder handles both formats.
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(config)
+	if err != nil {
+		return fmt.Errorf("config: parse %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func walk(value reflect.Value, prefix string, fn func(key string, value reflect.Value) error) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		// Maps, such as rate_limit.keys, can only be set in the config file.
+		if name == "" || name == "-" || field.Type.Kind() == reflect.Map {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		var err error
+		if field.Type.Kind() == reflect.Struct {
+			err = walk(value.Field(i), key, fn)
+		} else {
+			err = fn(key, value.Field(i))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func set(value reflect.Value, raw string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		number, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(number)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(boolean)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config type %s", value.Type())
+	}
+
+	return nil
+}
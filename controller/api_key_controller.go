@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type ApiKeyController interface {
+	CreateApiKey(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	RotateApiKey(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	RevokeApiKey(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetApiKeyById(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetAllApiKey(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
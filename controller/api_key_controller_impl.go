@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/web"
+	"golang-restful-api/service"
+	"net/http"
+)
+
+type ApiKeyControllerImplementation struct {
+	ApiKeyService service.ApiKeyService
+	Decoder       *RequestDecoder
+}
+
+func NewApiKeyController(apiKeyService service.ApiKeyService, decoder *RequestDecoder) ApiKeyController {
+	return &ApiKeyControllerImplementation{
+		ApiKeyService: apiKeyService,
+		Decoder:       decoder,
+	}
+}
+
+func (controller *ApiKeyControllerImplementation) CreateApiKey(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	apiKeyCreateRequest := web.ApiKeyCreateRequest{}
+	err := controller.Decoder.Decode(writer, request, &apiKeyCreateRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	apiKeyResponse, err := controller.ApiKeyService.Create(request.Context(), apiKeyCreateRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	writeIssuedApiKey(writer, apiKeyResponse)
+}
+
+func (controller *ApiKeyControllerImplementation) RotateApiKey(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	apiKeyId, err := pathId(params, "apiKeyId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	apiKeyResponse, err := controller.ApiKeyService.Rotate(request.Context(), apiKeyId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	writeIssuedApiKey(writer, apiKeyResponse)
+}
+
+func (controller *ApiKeyControllerImplementation) RevokeApiKey(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	apiKeyId, err := pathId(params, "apiKeyId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	err = controller.ApiKeyService.Revoke(request.Context(), apiKeyId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ApiKeyControllerImplementation) GetApiKeyById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	apiKeyId, err := pathId(params, "apiKeyId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	apiKeyResponse, err := controller.ApiKeyService.FindById(request.Context(), apiKeyId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   apiKeyResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ApiKeyControllerImplementation) GetAllApiKey(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	apiKeyResponses, err := controller.ApiKeyService.FindAll(request.Context())
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   apiKeyResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+// writeIssuedApiKey keeps the one response that carries a secret out of
+// caches.
+func writeIssuedApiKey(writer http.ResponseWriter, apiKeyResponse web.ApiKeyIssuedResponse) {
+	writer.Header().Set("Cache-Control", "no-store")
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   apiKeyResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
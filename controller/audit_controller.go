@@ -0,0 +1,10 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type AuditController interface {
+	GetAllAuditEntry(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/web"
+	"golang-restful-api/service"
+	"net/http"
+)
+
+type AuditControllerImplementation struct {
+	AuditService service.AuditService
+}
+
+func NewAuditController(auditService service.AuditService) AuditController {
+	return &AuditControllerImplementation{
+		AuditService: auditService,
+	}
+}
+
+// GetAllAuditEntry filters by the query parameters actor, entity_id, action,
+// from and to, and returns up to limit entries, newest first.
+func (controller *AuditControllerImplementation) GetAllAuditEntry(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	auditQuery, err := parseAuditQuery(request)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	auditEntryResponses, err := controller.AuditService.FindAll(request.Context(), auditQuery)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   auditEntryResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func parseAuditQuery(request *http.Request) (auditQuery web.AuditQuery, err error) {
+	query := request.URL.Query()
+	auditQuery.Actor = query.Get("actor")
+	auditQuery.Action = query.Get("action")
+
+	auditQuery.EntityId, err = queryInt(query, "entity_id")
+	if err != nil {
+		return auditQuery, err
+	}
+	auditQuery.Limit, err = queryInt(query, "limit")
+	if err != nil {
+		return auditQuery, err
+	}
+	auditQuery.From, err = queryTime(query, "from")
+	if err != nil {
+		return auditQuery, err
+	}
+	auditQuery.To, err = queryTime(query, "to")
+
+	return auditQuery, err
+}
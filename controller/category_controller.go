@@ -9,6 +9,14 @@ type CategoryController interface {
 	CreateCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	UpdateCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	DeleteCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	RestoreCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	PurgeCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	MoveCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	GetCategoryById(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetCategoryBySlug(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetCategoryChildren(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetCategoryAncestors(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetCategorySubtree(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetCategoryTree(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	GetAllCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 }
@@ -2,28 +2,40 @@ package controller
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"golang-restful-api/exception"
 	"golang-restful-api/helper"
 	"golang-restful-api/model/web"
 	"golang-restful-api/service"
 	"net/http"
-	"strconv"
+	"net/url"
 )
 
 type CategoryControllerImplementation struct {
 	CategoryService service.CategoryService
+	Decoder         *RequestDecoder
 }
 
-func NewCategoryController(categoryService service.CategoryService) CategoryController {
+func NewCategoryController(categoryService service.CategoryService, decoder *RequestDecoder) CategoryController {
 	return &CategoryControllerImplementation{
 		CategoryService: categoryService,
+		Decoder:         decoder,
 	}
 }
 
 func (controller *CategoryControllerImplementation) CreateCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	categoryCreateRequest := web.CategoryCreateRequest{}
-	helper.ReadFromRequestBody(request, &categoryCreateRequest)
+	err := controller.Decoder.Decode(writer, request, &categoryCreateRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryResponse, err := controller.CategoryService.Create(request.Context(), categoryCreateRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
 
-	categoryResponse := controller.CategoryService.Create(request.Context(), categoryCreateRequest)
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: http.StatusText(http.StatusOK),
@@ -35,13 +47,25 @@ func (controller *CategoryControllerImplementation) CreateCategory(writer http.R
 
 func (controller *CategoryControllerImplementation) UpdateCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	categoryUpdateRequest := web.CategoryUpdateRequest{}
-	helper.ReadFromRequestBody(request, &categoryUpdateRequest)
+	err := controller.Decoder.Decode(writer, request, &categoryUpdateRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
 
-	categoryId, err := strconv.Atoi(params.ByName("categoryId"))
-	helper.PanicIfError(err)
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
 	categoryUpdateRequest.Id = categoryId
 
-	categoryResponse := controller.CategoryService.Update(request.Context(), categoryUpdateRequest)
+	categoryResponse, err := controller.CategoryService.Update(request.Context(), categoryUpdateRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: http.StatusText(http.StatusOK),
@@ -51,24 +75,120 @@ func (controller *CategoryControllerImplementation) UpdateCategory(writer http.R
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
+// DeleteCategory takes the delete policy for child categories from the
+// children query parameter.
 func (controller *CategoryControllerImplementation) DeleteCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryId, err := strconv.Atoi(params.ByName("categoryId"))
-	helper.PanicIfError(err)
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryDeleteRequest := web.CategoryDeleteRequest{
+		Id:       categoryId,
+		Children: request.URL.Query().Get("children"),
+	}
+
+	err = controller.CategoryService.Delete(request.Context(), categoryDeleteRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CategoryControllerImplementation) RestoreCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryResponse, err := controller.CategoryService.Restore(request.Context(), categoryId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   categoryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CategoryControllerImplementation) PurgeCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	err = controller.CategoryService.Purge(request.Context(), categoryId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CategoryControllerImplementation) MoveCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryMoveRequest := web.CategoryMoveRequest{}
+	err := controller.Decoder.Decode(writer, request, &categoryMoveRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+	categoryMoveRequest.Id = categoryId
+
+	categoryResponse, err := controller.CategoryService.Move(request.Context(), categoryMoveRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
 
-	controller.CategoryService.Delete(request.Context(), categoryId)
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: http.StatusText(http.StatusOK),
+		Data:   categoryResponse,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
 func (controller *CategoryControllerImplementation) GetCategoryById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryId, err := strconv.Atoi(params.ByName("categoryId"))
-	helper.PanicIfError(err)
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryResponse, err := controller.CategoryService.FindById(request.Context(), categoryId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
 
-	categoryResponse := controller.CategoryService.FindById(request.Context(), categoryId)
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: http.StatusText(http.StatusOK),
@@ -78,8 +198,70 @@ func (controller *CategoryControllerImplementation) GetCategoryById(writer http.
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
-func (controller *CategoryControllerImplementation) GetAllCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryResponses := controller.CategoryService.FindAll(request.Context())
+// GetCategoryBySlug answers an old slug of a category with a permanent
+// redirect to its current slug.
+func (controller *CategoryControllerImplementation) GetCategoryBySlug(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryResponse, moved, err := controller.CategoryService.FindBySlug(request.Context(), params.ByName("slug"))
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	code := http.StatusOK
+	if moved {
+		code = http.StatusMovedPermanently
+		location := url.URL{Path: "/api/categories/by-slug/" + categoryResponse.Slug, RawQuery: request.URL.RawQuery}
+		writer.Header().Set("Location", location.String())
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(code)
+
+	webResponse := web.WebResponse{
+		Code:   code,
+		Status: http.StatusText(code),
+		Data:   categoryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CategoryControllerImplementation) GetCategoryChildren(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryResponses, err := controller.CategoryService.FindChildren(request.Context(), categoryId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   categoryResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+// GetCategoryAncestors returns the breadcrumb of a category, root first.
+func (controller *CategoryControllerImplementation) GetCategoryAncestors(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryResponses, err := controller.CategoryService.FindAncestors(request.Context(), categoryId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: http.StatusText(http.StatusOK),
@@ -88,3 +270,98 @@ func (controller *CategoryControllerImplementation) GetAllCategory(writer http.R
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
+
+func (controller *CategoryControllerImplementation) GetCategorySubtree(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryId, err := pathId(params, "categoryId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryTreeResponse, err := controller.CategoryService.FindSubtree(request.Context(), categoryId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   categoryTreeResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CategoryControllerImplementation) GetCategoryTree(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryTreeResponses, err := controller.CategoryService.FindTree(request.Context())
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   categoryTreeResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+// GetAllCategory returns one page of the categories matching the query
+// parameters name, name_prefix, name_contains, min_id, max_id and
+// include_deleted, ordered by sort and selected by limit and either offset
+// or cursor.
+func (controller *CategoryControllerImplementation) GetAllCategory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryListRequest, err := parseCategoryListRequest(request)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	categoryPageResponse, err := controller.CategoryService.FindPage(request.Context(), categoryListRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	writeLinks(writer, request, categoryPageResponse.Page)
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   categoryPageResponse.Categories,
+		Page:   &categoryPageResponse.Page,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func parseCategoryListRequest(request *http.Request) (categoryListRequest web.CategoryListRequest, err error) {
+	query := request.URL.Query()
+	categoryListRequest.Name = query.Get("name")
+	categoryListRequest.NamePrefix = query.Get("name_prefix")
+	categoryListRequest.NameContains = query.Get("name_contains")
+	categoryListRequest.Sort = query.Get("sort")
+	categoryListRequest.Cursor = query.Get("cursor")
+
+	categoryListRequest.IncludeDeleted, err = queryBool(query, "include_deleted")
+	if err != nil {
+		return categoryListRequest, err
+	}
+	categoryListRequest.MinId, err = queryInt(query, "min_id")
+	if err != nil {
+		return categoryListRequest, err
+	}
+	categoryListRequest.MaxId, err = queryInt(query, "max_id")
+	if err != nil {
+		return categoryListRequest, err
+	}
+	categoryListRequest.Limit, err = queryInt(query, "limit")
+	if err != nil {
+		return categoryListRequest, err
+	}
+	categoryListRequest.Offset, err = queryInt(query, "offset")
+
+	return categoryListRequest, err
+}
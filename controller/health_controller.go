@@ -0,0 +1,11 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type HealthController interface {
+	Liveness(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Readiness(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
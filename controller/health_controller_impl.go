@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang-restful-api/health"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/web"
+	"net/http"
+)
+
+type HealthControllerImplementation struct {
+	Health *health.Health
+}
+
+func NewHealthController(health *health.Health) HealthController {
+	return &HealthControllerImplementation{
+		Health: health,
+	}
+}
+
+func (controller *HealthControllerImplementation) Liveness(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   health.Report{Status: health.StatusUp},
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *HealthControllerImplementation) Readiness(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	report := controller.Health.Check(request.Context())
+
+	code := http.StatusOK
+	if report.Status != health.StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(code)
+
+	webResponse := web.WebResponse{
+		Code:   code,
+		Status: http.StatusText(code),
+		Data:   report,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
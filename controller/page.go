@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"golang-restful-api/model/web"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// writeLinks sets the RFC 8288 Link header of a list response. Links keep
+// the request's other query parameters and replace its position.
+func writeLinks(writer http.ResponseWriter, request *http.Request, page web.PageResponse) {
+	links := []string{link(request, "first", "")}
+	if page.PrevCursor != "" {
+		links = append(links, link(request, "prev", page.PrevCursor))
+	}
+	if page.NextCursor != "" {
+		links = append(links, link(request, "next", page.NextCursor))
+	}
+
+	writer.Header().Set("Link", strings.Join(links, ", "))
+}
+
+func link(request *http.Request, rel string, cursor string) string {
+	query := request.URL.Query()
+	query.Del("offset")
+	query.Del("cursor")
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	target := url.URL{Path: request.URL.Path, RawQuery: query.Encode()}
+
+	return "<" + target.String() + `>; rel="` + rel + `"`
+}
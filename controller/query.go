@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"errors"
+	"golang-restful-api/exception"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// queryInt parses an optional integer query parameter, zero when absent.
+func queryInt(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, exception.NewBadRequestError(name + " is out of range")
+		}
+		return 0, exception.NewBadRequestError(name + " must be an integer")
+	}
+
+	return number, nil
+}
+
+// queryBool parses an optional boolean query parameter, false when absent.
+func queryBool(query url.Values, name string) (bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	boolean, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, exception.NewBadRequestError(name + " must be true or false")
+	}
+
+	return boolean, nil
+}
+
+// queryTime parses an optional RFC 3339 query parameter, nil when absent.
+func queryTime(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, exception.NewBadRequestError(name + " must be an RFC 3339 time")
+	}
+
+	return &parsed, nil
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"golang-restful-api/exception"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RequestDecoder reads JSON request bodies and turns every way a client can
+// get them wrong into a BadRequestError or UnsupportedMediaTypeError.
+type RequestDecoder struct {
+	MaxBodyBytes int64
+	Strict       bool
+}
+
+func NewRequestDecoder(maxBodyBytes int64, strict bool) *RequestDecoder {
+	return &RequestDecoder{MaxBodyBytes: maxBodyBytes, Strict: strict}
+}
+
+func (decoder *RequestDecoder) Decode(writer http.ResponseWriter, request *http.Request, result interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return exception.NewUnsupportedMediaTypeError("Content-Type must be application/json")
+	}
+
+	body := http.MaxBytesReader(writer, request.Body, decoder.MaxBodyBytes)
+	jsonDecoder := json.NewDecoder(body)
+	if decoder.Strict {
+		jsonDecoder.DisallowUnknownFields()
+	}
+
+	err = jsonDecoder.Decode(result)
+	if err != nil {
+		return decodeError(err)
+	}
+
+	err = jsonDecoder.Decode(&struct{}{})
+	if !errors.Is(err, io.EOF) {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return decodeError(err)
+		}
+		return exception.NewBadRequestError("request body must contain a single JSON value")
+	}
+
+	return nil
+}
+
+func decodeError(err error) error {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return exception.NewBadRequestError("request body must not be empty")
+	case errors.As(err, &syntaxError):
+		return exception.NewBadRequestError(fmt.Sprintf("request body contains malformed JSON at offset %d", syntaxError.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return exception.NewBadRequestError("request body contains malformed JSON")
+	case errors.As(err, &typeError):
+		if typeError.Field == "" {
+			return exception.NewBadRequestError(fmt.Sprintf("request body must be a JSON object, not %s", typeError.Value))
+		}
+		return exception.NewBadRequestError(fmt.Sprintf("field %q must be of type %s, not %s", typeError.Field, typeError.Type, typeError.Value))
+	case errors.As(err, &maxBytesError):
+		return exception.NewBadRequestError(fmt.Sprintf("request body must not be larger than %d bytes", maxBytesError.Limit))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for unknown fields.
+		return exception.NewBadRequestError("request body contains unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return exception.NewBadRequestError("request body is invalid: " + err.Error())
+	}
+}
+
+// pathId parses a numeric id path parameter. Ids are INT columns, so
+// larger numbers are rejected here rather than by the database.
+func pathId(params httprouter.Params, name string) (int, error) {
+	id, err := strconv.ParseInt(params.ByName(name), 10, 32)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, exception.NewBadRequestError(name + " is out of range")
+		}
+		return 0, exception.NewBadRequestError(name + " must be an integer")
+	}
+
+	if id < 1 {
+		return 0, exception.NewBadRequestError(name + " must be a positive integer")
+	}
+
+	return int(id), nil
+}
@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type UserController interface {
+	CreateUser(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Register(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	UnlockUser(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetUserById(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	GetAllUser(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Login(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Logout(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	ChangePassword(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/web"
+	"golang-restful-api/service"
+	"net/http"
+)
+
+type UserControllerImplementation struct {
+	UserService service.UserService
+	Decoder     *RequestDecoder
+}
+
+func NewUserController(userService service.UserService, decoder *RequestDecoder) UserController {
+	return &UserControllerImplementation{
+		UserService: userService,
+		Decoder:     decoder,
+	}
+}
+
+func (controller *UserControllerImplementation) CreateUser(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userCreateRequest := web.UserCreateRequest{}
+	err := controller.Decoder.Decode(writer, request, &userCreateRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	userResponse, err := controller.UserService.Create(request.Context(), userCreateRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   userResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *UserControllerImplementation) Register(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	registerRequest := web.RegisterRequest{}
+	err := controller.Decoder.Decode(writer, request, &registerRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	userResponse, err := controller.UserService.Register(request.Context(), registerRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   userResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *UserControllerImplementation) UnlockUser(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userId, err := pathId(params, "userId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	err = controller.UserService.Unlock(request.Context(), userId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *UserControllerImplementation) GetUserById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userId, err := pathId(params, "userId")
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	userResponse, err := controller.UserService.FindById(request.Context(), userId)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   userResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *UserControllerImplementation) GetAllUser(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userResponses, err := controller.UserService.FindAll(request.Context())
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   userResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *UserControllerImplementation) Login(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	loginRequest := web.LoginRequest{}
+	err := controller.Decoder.Decode(writer, request, &loginRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	loginResponse, err := controller.UserService.Login(request.Context(), loginRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	writer.Header().Set("Cache-Control", "no-store")
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   loginResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *UserControllerImplementation) Logout(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	err := controller.UserService.Logout(request.Context())
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *UserControllerImplementation) ChangePassword(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	passwordChangeRequest := web.PasswordChangeRequest{}
+	err := controller.Decoder.Decode(writer, request, &passwordChangeRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	err = controller.UserService.ChangePassword(request.Context(), passwordChangeRequest)
+	if err != nil {
+		exception.WriteError(writer, request, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,15 @@
+package exception
+
+type ConflictError struct {
+	Message string
+	// Conflicting optionally describes the existing resource.
+	Conflicting interface{}
+}
+
+func NewConflictError(message string) ConflictError {
+	return ConflictError{Message: message}
+}
+
+func (err ConflictError) Error() string {
+	return err.Message
+}
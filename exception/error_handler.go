@@ -1,13 +1,27 @@
 package exception
 
 import (
+	"errors"
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"golang-restful-api/helper"
+	"golang-restful-api/i18n"
 	"golang-restful-api/model/web"
+	"golang-restful-api/reporter"
+	"log"
 	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
 )
 
+// ErrorHandler is the router's panic handler. Expected failures are returned
+// as errors and written with WriteError, so a panic here is always a bug.
 func ErrorHandler(w http.ResponseWriter, r *http.Request, err interface{}) {
+	internalServerError(w, r, err)
+}
+
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
 	if notFoundError(w, r, err) {
 		return
 	}
@@ -16,23 +30,86 @@ func ErrorHandler(w http.ResponseWriter, r *http.Request, err interface{}) {
 		return
 	}
 
+	if badRequestError(w, r, err) {
+		return
+	}
+
+	if unsupportedMediaTypeError(w, r, err) {
+		return
+	}
+
+	if unauthorizedError(w, r, err) {
+		return
+	}
+
+	if forbiddenError(w, r, err) {
+		return
+	}
+
+	if conflictError(w, r, err) {
+		return
+	}
+
+	if unavailableError(w, r, err) {
+		return
+	}
+
 	internalServerError(w, r, err)
 }
 
-func validationErrors(w http.ResponseWriter, r *http.Request, err interface{}) bool {
-	exception, ok := err.(validator.ValidationErrors)
+func validationErrors(w http.ResponseWriter, r *http.Request, err error) bool {
+	var exception ValidationError
+
+	if errors.As(err, &exception) {
+		WriteErrorResponse(w, r, http.StatusBadRequest, fieldErrors(w, r, exception))
+	} else {
+		return false
+	}
+
+	return true
+}
+
+// fieldErrors lists every failed rule, translated into the language the
+// locale middleware picked from Accept-Language.
+func fieldErrors(w http.ResponseWriter, r *http.Request, exception ValidationError) interface{} {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(exception.Err, &validationErrors) {
+		return exception.Error()
+	}
+
+	translator := i18n.TranslatorFromContext(r.Context())
+	if translator != nil {
+		w.Header().Set("Content-Language", strings.ReplaceAll(translator.Locale(), "_", "-"))
+	}
 
-	if ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
+	var responses []web.FieldErrorResponse
+	for _, fieldError := range validationErrors {
+		message := fieldError.Error()
+		if translator != nil {
+			message = fieldError.Translate(translator)
+		}
 
-		webResponse := web.WebResponse{
-			Code:   http.StatusBadRequest,
-			Status: http.StatusText(http.StatusBadRequest),
-			Data:   exception.Error(),
+		field := fieldError.Namespace()
+		if index := strings.Index(field, "."); index >= 0 {
+			field = field[index+1:]
 		}
 
-		helper.WriteToResponseBody(w, webResponse)
+		responses = append(responses, web.FieldErrorResponse{
+			Field:   field,
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: message,
+		})
+	}
+
+	return responses
+}
+
+func notFoundError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var exception NotFoundError
+
+	if errors.As(err, &exception) {
+		WriteErrorResponse(w, r, http.StatusNotFound, exception.Message)
 	} else {
 		return false
 	}
@@ -40,20 +117,74 @@ func validationErrors(w http.ResponseWriter, r *http.Request, err interface{}) b
 	return true
 }
 
-func notFoundError(w http.ResponseWriter, r *http.Request, err interface{}) bool {
-	exception, ok := err.(NotFoundError)
+func badRequestError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var exception BadRequestError
 
-	if ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
+	if errors.As(err, &exception) {
+		WriteErrorResponse(w, r, http.StatusBadRequest, exception.Message)
+	} else {
+		return false
+	}
 
-		webResponse := web.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: http.StatusText(http.StatusNotFound),
-			Data:   exception.Error,
-		}
+	return true
+}
+
+func unsupportedMediaTypeError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var exception UnsupportedMediaTypeError
+
+	if errors.As(err, &exception) {
+		WriteErrorResponse(w, r, http.StatusUnsupportedMediaType, exception.Message)
+	} else {
+		return false
+	}
+
+	return true
+}
+
+func unauthorizedError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var exception UnauthorizedError
+
+	if errors.As(err, &exception) {
+		WriteErrorResponse(w, r, http.StatusUnauthorized, exception.Message)
+	} else {
+		return false
+	}
+
+	return true
+}
+
+func forbiddenError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var exception ForbiddenError
+
+	if errors.As(err, &exception) {
+		WriteErrorResponse(w, r, http.StatusForbidden, exception.Message)
+	} else {
+		return false
+	}
+
+	return true
+}
+
+func conflictError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var exception ConflictError
+
+	if errors.As(err, &exception) {
+		WriteErrorResponse(w, r, http.StatusConflict, web.ConflictResponse{
+			Message:     exception.Message,
+			Conflicting: exception.Conflicting,
+		})
+	} else {
+		return false
+	}
+
+	return true
+}
+
+func unavailableError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var exception UnavailableError
 
-		helper.WriteToResponseBody(w, webResponse)
+	if errors.As(err, &exception) {
+		WriteErrorResponse(w, r, http.StatusServiceUnavailable, "service unavailable")
 	} else {
 		return false
 	}
@@ -61,15 +192,87 @@ func notFoundError(w http.ResponseWriter, r *http.Request, err interface{}) bool
 	return true
 }
 
+// internalServerError never shows the error to the client, since it may
+// contain SQL or driver messages. The client gets an error ID instead, and the
+// full error is sent to the reporter under that ID.
 func internalServerError(w http.ResponseWriter, r *http.Request, err interface{}) {
+	errorId := helper.NewId()
+
+	report := reporter.Report{
+		ErrorId:   errorId,
+		Time:      time.Now().UTC(),
+		Method:    r.Method,
+		Path:      r.URL.RequestURI(),
+		RequestId: helper.RequestIdFromContext(r.Context()),
+		Error:     fmt.Sprint(err),
+		Stack:     string(debug.Stack()),
+	}
+
+	errReport := reporter.FromContext(r.Context()).Report(r.Context(), report)
+	if errReport != nil {
+		log.Printf("error %s could not be reported: %v: %v", errorId, errReport, err)
+	}
+
+	WriteErrorResponse(w, r, http.StatusInternalServerError, web.InternalErrorResponse{
+		ErrorId: errorId,
+		Message: "internal server error",
+	})
+}
+
+// WriteErrorResponse writes data in the envelope the API has always used, or
+// as an RFC 7807 problem document when that format was configured or asked
+// for. Field errors become the problem's "errors" extension member.
+func WriteErrorResponse(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
+	if formatFromRequest(r) == FormatProblem {
+		writeProblem(w, r, code, data)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusInternalServerError)
+	w.WriteHeader(code)
 
 	webResponse := web.WebResponse{
-		Code:   http.StatusInternalServerError,
-		Status: http.StatusText(http.StatusInternalServerError),
-		Data:   err,
+		Code:   code,
+		Status: http.StatusText(code),
+		Data:   data,
 	}
 
 	helper.WriteToResponseBody(w, webResponse)
 }
+
+func writeProblem(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
+	problem := web.ProblemDetails{
+		Type:     problemType(http.StatusText(code)),
+		Title:    http.StatusText(code),
+		Status:   code,
+		Instance: r.URL.RequestURI(),
+	}
+
+	switch data := data.(type) {
+	case nil:
+	case string:
+		problem.Detail = data
+	case web.ConflictResponse:
+		problem.Detail = data.Message
+		problem.Conflicting = data.Conflicting
+	case web.InternalErrorResponse:
+		problem.Detail = data.Message
+		problem.ErrorId = data.ErrorId
+	case []web.FieldErrorResponse:
+		problem.Type = problemType("validation")
+		problem.Detail = "the request has invalid fields"
+		problem.Errors = data
+	default:
+		problem.Detail = fmt.Sprint(data)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(code)
+
+	helper.WriteToResponseBody(w, problem)
+}
+
+// problemType turns a title like "Not Found" into "/problems/not-found".
+func problemType(title string) string {
+	return "/problems/" + strings.ReplaceAll(strings.ToLower(title), " ", "-")
+}
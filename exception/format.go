@@ -0,0 +1,41 @@
+package exception
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatEnvelope Format = "envelope"
+	FormatProblem  Format = "problem"
+
+	ProblemContentType = "application/problem+json"
+)
+
+type formatContextKey struct{}
+
+func WithFormat(ctx context.Context, format Format) context.Context {
+	return context.WithValue(ctx, formatContextKey{}, format)
+}
+
+// formatFromRequest prefers a problem document the client asked for over the
+// format configured for the server.
+func formatFromRequest(r *http.Request) Format {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == ProblemContentType {
+			return FormatProblem
+		}
+	}
+
+	format, ok := r.Context().Value(formatContextKey{}).(Format)
+	if !ok {
+		return FormatEnvelope
+	}
+
+	return format
+}
@@ -1,15 +1,13 @@
 package exception
 
 type NotFoundError struct {
-	Error string
+	Message string
 }
 
-func NewNotFoundError(error string) NotFoundError {
-	return NotFoundError{Error: error}
+func NewNotFoundError(message string) NotFoundError {
+	return NotFoundError{Message: message}
 }
 
-func PanicNotFoundError(err error) {
-	if err != nil {
-		panic(NewNotFoundError(err.Error()))
-	}
+func (err NotFoundError) Error() string {
+	return err.Message
 }
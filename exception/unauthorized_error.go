@@ -0,0 +1,15 @@
+package exception
+
+// UnauthorizedError is a failed login. Requests without valid credentials
+// are answered by the auth middleware instead.
+type UnauthorizedError struct {
+	Message string
+}
+
+func NewUnauthorizedError(message string) UnauthorizedError {
+	return UnauthorizedError{Message: message}
+}
+
+func (err UnauthorizedError) Error() string {
+	return err.Message
+}
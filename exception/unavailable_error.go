@@ -0,0 +1,17 @@
+package exception
+
+type UnavailableError struct {
+	Err error
+}
+
+func NewUnavailableError(err error) UnavailableError {
+	return UnavailableError{Err: err}
+}
+
+func (err UnavailableError) Error() string {
+	return "service unavailable: " + err.Err.Error()
+}
+
+func (err UnavailableError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,13 @@
+package exception
+
+type UnsupportedMediaTypeError struct {
+	Message string
+}
+
+func NewUnsupportedMediaTypeError(message string) UnsupportedMediaTypeError {
+	return UnsupportedMediaTypeError{Message: message}
+}
+
+func (err UnsupportedMediaTypeError) Error() string {
+	return err.Message
+}
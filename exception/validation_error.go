@@ -0,0 +1,17 @@
+package exception
+
+type ValidationError struct {
+	Err error
+}
+
+func NewValidationError(err error) ValidationError {
+	return ValidationError{Err: err}
+}
+
+func (err ValidationError) Error() string {
+	return err.Err.Error()
+}
+
+func (err ValidationError) Unwrap() error {
+	return err.Err
+}
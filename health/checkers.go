@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (checker checkerFunc) Name() string {
+	return checker.name
+}
+
+func (checker checkerFunc) Check(ctx context.Context) error {
+	return checker.check(ctx)
+}
+
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, check: check}
+}
+
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+func NewDatabaseChecker(db Pinger) Checker {
+	return NewChecker("database", db.PingContext)
+}
+
+type VersionSource interface {
+	Version(ctx context.Context) (int, error)
+	Latest() int
+}
+
+func NewMigrationChecker(migrator VersionSource) Checker {
+	return NewChecker("migrations", func(ctx context.Context) error {
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+
+		if version != migrator.Latest() {
+			return fmt.Errorf("schema is at version %d, expected %d", version, migrator.Latest())
+		}
+		return nil
+	})
+}
+
+type ShutdownState interface {
+	IsShuttingDown() bool
+}
+
+func NewShutdownChecker(shutdown ShutdownState) Checker {
+	return NewChecker("shutdown", func(ctx context.Context) error {
+		if shutdown.IsShuttingDown() {
+			return errors.New("server is shutting down")
+		}
+		return nil
+	})
+}
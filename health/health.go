@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Health runs the registered readiness checks concurrently, giving each one
+// at most Timeout to answer.
+type Health struct {
+	Timeout  time.Duration
+	mutex    sync.RWMutex
+	checkers []Checker
+}
+
+func NewHealth(timeout time.Duration) *Health {
+	return &Health{Timeout: timeout}
+}
+
+func (health *Health) Register(checker Checker) {
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+
+	health.checkers = append(health.checkers, checker)
+	sort.SliceStable(health.checkers, func(i, j int) bool {
+		return health.checkers[i].Name() < health.checkers[j].Name()
+	})
+}
+
+func (health *Health) Check(ctx context.Context) Report {
+	health.mutex.RLock()
+	checkers := health.checkers
+	health.mutex.RUnlock()
+
+	results := make([]CheckResult, len(checkers))
+
+	var wait sync.WaitGroup
+	for i, checker := range checkers {
+		wait.Add(1)
+		go func(i int, checker Checker) {
+			defer wait.Done()
+			results[i] = health.run(ctx, checker)
+		}(i, checker)
+	}
+	wait.Wait()
+
+	report := Report{Status: StatusUp, Checks: map[string]CheckResult{}}
+	for i, checker := range checkers {
+		report.Checks[checker.Name()] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+func (health *Health) run(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, health.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := CheckResult{Status: StatusUp, Duration: time.Since(start).String()}
+
+	// /readyz is public, so driver and migration errors, which may name
+	// hosts, queries or files, are only logged.
+	if err != nil {
+		log.Printf("health: %s: %v", checker.Name(), err)
+		result.Status = StatusDown
+		result.Error = "unavailable"
+	}
+
+	return result
+}
@@ -0,0 +1,16 @@
+package helper
+
+import "context"
+
+type clientIpContextKey struct{}
+
+func WithClientIp(ctx context.Context, clientIp string) context.Context {
+	return context.WithValue(ctx, clientIpContextKey{}, clientIp)
+}
+
+// ClientIpFromContext returns "" outside of a request.
+func ClientIpFromContext(ctx context.Context) string {
+	clientIp, _ := ctx.Value(clientIpContextKey{}).(string)
+
+	return clientIp
+}
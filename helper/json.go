@@ -5,13 +5,6 @@ import (
 	"net/http"
 )
 
-func ReadFromRequestBody(request *http.Request, result interface{}) {
-	decoder := json.NewDecoder(request.Body)
-
-	errDecode := decoder.Decode(result)
-	PanicIfError(errDecode)
-}
-
 func WriteToResponseBody(writer http.ResponseWriter, response interface{}) {
 	writer.Header().Add("Content-Type", "application/json")
 	encoder := json.NewEncoder(writer)
@@ -1,14 +1,21 @@
 package helper
 
 import (
+	"encoding/json"
 	"golang-restful-api/model/domain"
 	"golang-restful-api/model/web"
+	"time"
 )
 
 func ToCategoryResponse(category domain.Category) web.CategoryResponse {
 	return web.CategoryResponse{
-		Id:   category.Id,
-		Name: category.Name,
+		Id:        category.Id,
+		ParentId:  intOrNil(category.ParentId),
+		Name:      category.Name,
+		Slug:      category.Slug,
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+		DeletedAt: timeOrNil(category.DeletedAt),
 	}
 }
 
@@ -20,3 +27,99 @@ func ToCategoryResponses(categories []domain.Category) []web.CategoryResponse {
 
 	return categoryResponses
 }
+
+func ToApiKeyResponse(apiKey domain.ApiKey) web.ApiKeyResponse {
+	return web.ApiKeyResponse{
+		Id:         apiKey.Id,
+		Name:       apiKey.Name,
+		Owner:      apiKey.Owner,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		CreatedAt:  apiKey.CreatedAt,
+		ExpiresAt:  timeOrNil(apiKey.ExpiresAt),
+		LastUsedAt: timeOrNil(apiKey.LastUsedAt),
+		Revoked:    apiKey.Revoked,
+	}
+}
+
+func ToApiKeyResponses(apiKeys []domain.ApiKey) []web.ApiKeyResponse {
+	var apiKeyResponses []web.ApiKeyResponse
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, ToApiKeyResponse(apiKey))
+	}
+
+	return apiKeyResponses
+}
+
+func ToUserResponse(user domain.User) web.UserResponse {
+	response := web.UserResponse{
+		Id:                user.Id,
+		Username:          user.Username,
+		Scopes:            user.Scopes,
+		CreatedAt:         user.CreatedAt,
+		PasswordChangedAt: user.PasswordChangedAt,
+	}
+	if user.LockedUntil.After(time.Now()) {
+		response.LockedUntil = &user.LockedUntil
+	}
+
+	return response
+}
+
+func ToUserResponses(users []domain.User) []web.UserResponse {
+	var userResponses []web.UserResponse
+	for _, user := range users {
+		userResponses = append(userResponses, ToUserResponse(user))
+	}
+
+	return userResponses
+}
+
+func ToAuditEntryResponse(entry domain.AuditEntry) web.AuditEntryResponse {
+	return web.AuditEntryResponse{
+		Id:         entry.Id,
+		OccurredAt: entry.OccurredAt,
+		Actor:      entry.Actor,
+		ActorName:  entry.ActorName,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityId,
+		Before:     rawJSONOrNull(entry.Before),
+		After:      rawJSONOrNull(entry.After),
+		RequestId:  entry.RequestId,
+		ClientIp:   entry.ClientIp,
+	}
+}
+
+func ToAuditEntryResponses(entries []domain.AuditEntry) []web.AuditEntryResponse {
+	var auditEntryResponses []web.AuditEntryResponse
+	for _, entry := range entries {
+		auditEntryResponses = append(auditEntryResponses, ToAuditEntryResponse(entry))
+	}
+
+	return auditEntryResponses
+}
+
+func rawJSONOrNull(value string) json.RawMessage {
+	if value == "" {
+		return json.RawMessage("null")
+	}
+
+	return json.RawMessage(value)
+}
+
+func intOrNil(value int) *int {
+	if value == 0 {
+		return nil
+	}
+
+	return &value
+}
+
+func timeOrNil(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+
+	return &value
+}
@@ -0,0 +1,30 @@
+package helper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type requestIdContextKey struct{}
+
+// NewId returns a random 128-bit identifier in hex, used for request and
+// error IDs.
+func NewId() string {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	PanicIfError(err)
+
+	return hex.EncodeToString(id)
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// RequestIdFromContext returns "" outside of a request.
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+
+	return requestId
+}
@@ -1,16 +1,21 @@
 package helper
 
-import "database/sql"
+import "database/sql/driver"
 
-func CommitOrRollback(tx *sql.Tx) {
-	err := recover()
-	if err != nil {
-		errRollback := tx.Rollback()
-		PanicIfError(errRollback)
+// CommitOrRollback must be deferred with a pointer to the caller's named
+// error result: the transaction is rolled back when that error is set or the
+// caller panics, and committed otherwise.
+func CommitOrRollback(tx driver.Tx, err *error) {
+	recovered := recover()
+	if recovered != nil {
+		_ = tx.Rollback()
+		panic(recovered)
+	}
 
-		panic(err)
-	} else {
-		errCommit := tx.Commit()
-		PanicIfError(errCommit)
+	if *err != nil {
+		_ = tx.Rollback()
+		return
 	}
+
+	*err = tx.Commit()
 }
@@ -0,0 +1,116 @@
+package i18n
+
+import (
+	"context"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type contextKey struct{}
+
+type language struct {
+	locale   locales.Translator
+	register func(validate *validator.Validate, translator ut.Translator) error
+}
+
+var languages = []language{
+	{locale: en.New(), register: enTranslations.RegisterDefaultTranslations},
+	{locale: id.New(), register: idTranslations.RegisterDefaultTranslations},
+	{locale: es.New(), register: esTranslations.RegisterDefaultTranslations},
+	{locale: fr.New(), register: frTranslations.RegisterDefaultTranslations},
+}
+
+// NewTranslator registers the validator's messages for every supported
+// language. English is the fallback when no requested language matches.
+func NewTranslator(validate *validator.Validate) (*ut.UniversalTranslator, error) {
+	var supported []locales.Translator
+	for _, language := range languages {
+		supported = append(supported, language.locale)
+	}
+
+	universalTranslator := ut.New(supported[0], supported...)
+
+	for _, language := range languages {
+		translator, _ := universalTranslator.GetTranslator(language.locale.Locale())
+
+		err := language.register(validate, translator)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return universalTranslator, nil
+}
+
+func WithTranslator(ctx context.Context, translator ut.Translator) context.Context {
+	return context.WithValue(ctx, contextKey{}, translator)
+}
+
+// TranslatorFromContext returns nil when the request was not routed through
+// the locale middleware.
+func TranslatorFromContext(ctx context.Context) ut.Translator {
+	translator, _ := ctx.Value(contextKey{}).(ut.Translator)
+	return translator
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header ordered
+// by preference, each followed by its base language ("pt-BR" gives "pt_BR",
+// "pt"), in the form universal-translator expects.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(param[2:], 64)
+				if err == nil {
+					quality = parsed
+				}
+			}
+		}
+
+		if quality > 0 {
+			tags = append(tags, weighted{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	var result []string
+	for _, tag := range tags {
+		parts := strings.Split(tag.tag, "-")
+		base := strings.ToLower(parts[0])
+		if len(parts) > 1 {
+			result = append(result, base+"_"+strings.ToUpper(parts[1]))
+		}
+		result = append(result, base)
+	}
+
+	return result
+}
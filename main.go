@@ -1,32 +1,13 @@
 package main
 
 import (
-	"github.com/go-playground/validator/v10"
 	_ "github.com/go-sql-driver/mysql"
-	"golang-restful-api/app"
-	"golang-restful-api/controller"
-	"golang-restful-api/helper"
-	"golang-restful-api/middleware"
-	"golang-restful-api/repository"
-	"golang-restful-api/service"
-	"net/http"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"golang-restful-api/cli"
+	"os"
 )
 
 func main() {
-	db := app.NewDB()
-	validate := validator.New()
-
-	categoryRepository := repository.NewCategoryRepository()
-	categoryService := service.NewCategoryService(categoryRepository, db, validate)
-	categoryController := controller.NewCategoryController(categoryService)
-
-	router := app.NewRouter(categoryController)
-
-	server := http.Server{
-		Addr:    "localhost:3000",
-		Handler: middleware.NewAuthMiddleware(router),
-	}
-
-	err := server.ListenAndServe()
-	helper.PanicIfError(err)
+	os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
@@ -1,32 +1,69 @@
 package middleware
 
 import (
-	"golang-restful-api/helper"
-	"golang-restful-api/model/web"
+	"errors"
+	"golang-restful-api/auth"
+	"golang-restful-api/exception"
 	"net/http"
 )
 
+// AuthMiddleware asks each authenticator in turn and puts the first identity
+// found into the request context.
 type AuthMiddleware struct {
-	Handler http.Handler
+	Handler        http.Handler
+	Authenticators []auth.Authenticator
+	PublicPaths    map[string]bool
+	// Unauthorized answers requests without valid credentials. It can be
+	// wrapped in a RateLimitMiddleware so that failed attempts draw from the
+	// bucket of the client's IP, like other anonymous requests.
+	Unauthorized http.Handler
 }
 
-func NewAuthMiddleware(handler http.Handler) *AuthMiddleware {
-	return &AuthMiddleware{Handler: handler}
+func NewAuthMiddleware(handler http.Handler, authenticators []auth.Authenticator, publicPaths ...string) *AuthMiddleware {
+	middleware := &AuthMiddleware{Handler: handler, Authenticators: authenticators, PublicPaths: map[string]bool{}}
+	for _, path := range publicPaths {
+		middleware.PublicPaths[path] = true
+	}
+	middleware.Unauthorized = http.HandlerFunc(middleware.unauthorized)
+
+	return middleware
 }
 
 func (middleware AuthMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	if "RAHASIA" == request.Header.Get("X-API-Key") {
+	if middleware.PublicPaths[request.URL.Path] {
 		middleware.Handler.ServeHTTP(writer, request)
-	} else {
-		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusUnauthorized)
-
-		webResponse := web.WebResponse{
-			Code:   http.StatusUnauthorized,
-			Status: http.StatusText(http.StatusUnauthorized),
-			Data:   nil,
+		return
+	}
+
+	for _, authenticator := range middleware.Authenticators {
+		identity, err := authenticator.Authenticate(request)
+		if errors.Is(err, auth.ErrNoCredentials) {
+			continue
+		}
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			break
+		}
+		if err != nil {
+			exception.WriteError(writer, request, err)
+			return
 		}
 
-		helper.WriteToResponseBody(writer, webResponse)
+		ctx := auth.WithIdentity(request.Context(), identity)
+		middleware.Handler.ServeHTTP(writer, request.WithContext(ctx))
+		return
 	}
+
+	middleware.Unauthorized.ServeHTTP(writer, request)
+}
+
+func (middleware AuthMiddleware) unauthorized(writer http.ResponseWriter, request *http.Request) {
+	challenges := map[string]bool{}
+	for _, authenticator := range middleware.Authenticators {
+		if challenger, ok := authenticator.(auth.Challenger); ok && !challenges[challenger.Challenge()] {
+			challenges[challenger.Challenge()] = true
+			writer.Header().Add("WWW-Authenticate", challenger.Challenge())
+		}
+	}
+
+	exception.WriteErrorResponse(writer, request, http.StatusUnauthorized, nil)
 }
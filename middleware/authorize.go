@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang-restful-api/auth"
+	"golang-restful-api/exception"
+	"net/http"
+)
+
+// Authorize wraps the handle of one route with its policy check. It panics
+// when the policy does not declare the route, so a new route cannot be
+// registered without deciding who may call it.
+func Authorize(policy auth.Policy, method string, pattern string, handle httprouter.Handle) httprouter.Handle {
+	if !policy.Declares(method, pattern) {
+		panic("authorization policy does not declare " + method + " " + pattern)
+	}
+
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		err := policy.Authorize(request.Context(), method, pattern)
+		if err != nil {
+			exception.WriteError(writer, request, err)
+			return
+		}
+
+		handle(writer, request, params)
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"golang-restful-api/helper"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIpMiddleware records the address of the client for rate limiting
+// and auditing.
+type ClientIpMiddleware struct {
+	Handler http.Handler
+	// Header names a header set by a trusted proxy, such as X-Forwarded-For,
+	// whose last address is the client. Empty uses the connection's address.
+	Header string
+}
+
+func NewClientIpMiddleware(handler http.Handler, header string) *ClientIpMiddleware {
+	return &ClientIpMiddleware{Handler: handler, Header: header}
+}
+
+func (middleware ClientIpMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	ctx := helper.WithClientIp(request.Context(), clientIp(request, middleware.Header))
+	middleware.Handler.ServeHTTP(writer, request.WithContext(ctx))
+}
+
+func clientIp(request *http.Request, header string) string {
+	if header != "" {
+		// A proxy may append its own line instead of extending the first.
+		values := strings.Split(strings.Join(request.Header.Values(header), ","), ",")
+		for i := len(values) - 1; i >= 0; i-- {
+			if ip := strings.TrimSpace(values[i]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return host
+}
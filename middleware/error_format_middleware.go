@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"golang-restful-api/exception"
+	"net/http"
+)
+
+type ErrorFormatMiddleware struct {
+	Handler http.Handler
+	Format  exception.Format
+}
+
+func NewErrorFormatMiddleware(handler http.Handler, format exception.Format) *ErrorFormatMiddleware {
+	return &ErrorFormatMiddleware{Handler: handler, Format: format}
+}
+
+func (middleware ErrorFormatMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	ctx := exception.WithFormat(request.Context(), middleware.Format)
+	middleware.Handler.ServeHTTP(writer, request.WithContext(ctx))
+}
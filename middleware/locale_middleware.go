@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	ut "github.com/go-playground/universal-translator"
+	"golang-restful-api/i18n"
+	"net/http"
+)
+
+type LocaleMiddleware struct {
+	Handler    http.Handler
+	Translator *ut.UniversalTranslator
+}
+
+func NewLocaleMiddleware(handler http.Handler, translator *ut.UniversalTranslator) *LocaleMiddleware {
+	return &LocaleMiddleware{Handler: handler, Translator: translator}
+}
+
+func (middleware LocaleMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	translator, _ := middleware.Translator.FindTranslator(i18n.ParseAcceptLanguage(request.Header.Get("Accept-Language"))...)
+
+	ctx := i18n.WithTranslator(request.Context(), translator)
+	middleware.Handler.ServeHTTP(writer, request.WithContext(ctx))
+}
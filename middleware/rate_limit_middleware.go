@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"golang-restful-api/auth"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"golang-restful-api/ratelimit"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitMiddleware runs after AuthMiddleware so that authenticated
+// clients are limited by identity; anonymous requests are limited by the IP
+// that ClientIpMiddleware found. Requests that AuthMiddleware rejects reach
+// it through AuthMiddleware.Unauthorized.
+type RateLimitMiddleware struct {
+	Handler     http.Handler
+	Store       ratelimit.Store
+	Rules       ratelimit.Rules
+	ExemptPaths map[string]bool
+}
+
+func NewRateLimitMiddleware(handler http.Handler, store ratelimit.Store, rules ratelimit.Rules, exemptPaths ...string) *RateLimitMiddleware {
+	middleware := &RateLimitMiddleware{Handler: handler, Store: store, Rules: rules, ExemptPaths: map[string]bool{}}
+	for _, path := range exemptPaths {
+		middleware.ExemptPaths[path] = true
+	}
+
+	return middleware
+}
+
+func (middleware RateLimitMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if middleware.ExemptPaths[request.URL.Path] {
+		middleware.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	client := middleware.clientKey(request)
+
+	// The client bucket is checked first; a request it denies does not
+	// draw from the route bucket.
+	result, ok := middleware.take(request, client, middleware.Rules.ClientLimit(client))
+	if ok && result.Allowed {
+		route, limit, found := middleware.Rules.RouteLimit(request.Method, request.URL.Path)
+		if found {
+			routeResult, routeOk := middleware.take(request, client+" "+route, limit)
+			if routeOk && (!routeResult.Allowed || routeResult.Remaining < result.Remaining) {
+				result = routeResult
+			}
+		}
+	}
+
+	if !ok {
+		middleware.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	writer.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	writer.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	writer.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if !result.Allowed {
+		retryAfter := ceilSeconds(result.RetryAfter)
+		writer.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		exception.WriteErrorResponse(writer, request, http.StatusTooManyRequests,
+			"rate limit exceeded, retry in "+strconv.Itoa(retryAfter)+"s")
+		return
+	}
+
+	middleware.Handler.ServeHTTP(writer, request)
+}
+
+// take lets requests through when the store fails, so that an outage of a
+// shared store does not take the API down with it.
+func (middleware RateLimitMiddleware) take(request *http.Request, key string, limit ratelimit.Limit) (ratelimit.Result, bool) {
+	result, err := middleware.Store.Take(request.Context(), key, limit)
+	if err != nil {
+		log.Printf("ratelimit: %v", err)
+		return result, false
+	}
+
+	return result, true
+}
+
+func (middleware RateLimitMiddleware) clientKey(request *http.Request) string {
+	identity, ok := auth.IdentityFromContext(request.Context())
+	if ok {
+		return identity.Type + ":" + identity.Subject
+	}
+
+	ip := helper.ClientIpFromContext(request.Context())
+	if ip == "" {
+		ip = clientIp(request, "")
+	}
+
+	return "ip:" + ip
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
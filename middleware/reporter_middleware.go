@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"golang-restful-api/reporter"
+	"net/http"
+)
+
+type ReporterMiddleware struct {
+	Handler  http.Handler
+	Reporter reporter.Reporter
+}
+
+func NewReporterMiddleware(handler http.Handler, reporter reporter.Reporter) *ReporterMiddleware {
+	return &ReporterMiddleware{Handler: handler, Reporter: reporter}
+}
+
+func (middleware ReporterMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	ctx := reporter.WithReporter(request.Context(), middleware.Reporter)
+	middleware.Handler.ServeHTTP(writer, request.WithContext(ctx))
+}
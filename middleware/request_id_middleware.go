@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"golang-restful-api/helper"
+	"net/http"
+	"regexp"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestIdMiddleware keeps the request ID a proxy assigned, or creates one,
+// and echoes it in the response so logs on both sides can be joined.
+type RequestIdMiddleware struct {
+	Handler http.Handler
+}
+
+func NewRequestIdMiddleware(handler http.Handler) *RequestIdMiddleware {
+	return &RequestIdMiddleware{Handler: handler}
+}
+
+func (middleware RequestIdMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	requestId := request.Header.Get(RequestIdHeader)
+	if !requestIdPattern.MatchString(requestId) {
+		requestId = helper.NewId()
+	}
+
+	writer.Header().Set(RequestIdHeader, requestId)
+
+	ctx := helper.WithRequestId(request.Context(), requestId)
+	middleware.Handler.ServeHTTP(writer, request.WithContext(ctx))
+}
@@ -0,0 +1,100 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql
+var files embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Load returns the embedded migrations for a dialect ordered by version.
+func Load(dialect string) ([]Migration, error) {
+	return load(files, path.Join("sql", dialect))
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migration: no migrations for %s: %w", dir, err)
+	}
+
+	migrations := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration: unexpected file %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := migrations[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			migrations[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration: version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var result []Migration
+	for _, migration := range migrations {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration: version %d has no up migration", migration.Version)
+		}
+
+		sum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		result = append(result, *migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// statements splits a migration into individual statements, because not every
+// driver accepts several statements in one Exec. Statements must end with a
+// semicolon at the end of a line.
+func statements(script string) []string {
+	var result []string
+	for _, statement := range strings.SplitAfter(script, ";\n") {
+		statement = strings.TrimSpace(statement)
+		statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+		if statement != "" {
+			result = append(result, statement)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,391 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"golang-restful-api/repository"
+	"hash/fnv"
+	"time"
+)
+
+const (
+	tableName = "schema_migrations"
+	lockName  = "golang-restful-api:schema_migrations"
+)
+
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt string
+}
+
+type appliedMigration struct {
+	version   int
+	name      string
+	checksum  string
+	appliedAt string
+}
+
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type Migrator struct {
+	DB          *sql.DB
+	Dialect     repository.Dialect
+	Migrations  []Migration
+	LockTimeout time.Duration
+}
+
+func NewMigrator(db *sql.DB, dialect repository.Dialect) (*Migrator, error) {
+	migrations, err := Load(dialect.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DB:          db,
+		Dialect:     dialect,
+		Migrations:  migrations,
+		LockTimeout: time.Minute,
+	}, nil
+}
+
+func (migrator *Migrator) Latest() int {
+	if len(migrator.Migrations) == 0 {
+		return 0
+	}
+
+	return migrator.Migrations[len(migrator.Migrations)-1].Version
+}
+
+// Version returns the highest applied version, or 0 for an empty database.
+// Like Status, it only reads, and runs on every readiness probe.
+func (migrator *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+
+	err := migrator.withConn(ctx, false, func(conn executor) error {
+		applied, err := migrator.appliedIfExists(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if len(applied) > 0 {
+			version = applied[len(applied)-1].version
+		}
+		return nil
+	})
+
+	return version, err
+}
+
+func (migrator *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := migrator.withConn(ctx, false, func(conn executor) error {
+		applied, err := migrator.appliedIfExists(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		appliedAt := map[int]string{}
+		for _, migration := range applied {
+			appliedAt[migration.version] = migration.appliedAt
+		}
+
+		for _, migration := range migrator.Migrations {
+			at, ok := appliedAt[migration.Version]
+			statuses = append(statuses, Status{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Applied:   ok,
+				AppliedAt: at,
+			})
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+func (migrator *Migrator) Up(ctx context.Context) error {
+	return migrator.Goto(ctx, migrator.Latest())
+}
+
+func (migrator *Migrator) Down(ctx context.Context, steps int) error {
+	return migrator.withLock(ctx, func(conn executor) error {
+		applied, err := migrator.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		target := 0
+		if steps < len(applied) {
+			target = applied[len(applied)-1-steps].version
+		}
+
+		return migrator.migrate(ctx, conn, applied, target)
+	})
+}
+
+// Goto applies or reverts migrations until the database is at version.
+func (migrator *Migrator) Goto(ctx context.Context, version int) error {
+	if version != 0 && migrator.find(version) == nil {
+		return fmt.Errorf("migration: unknown version %d", version)
+	}
+
+	return migrator.withLock(ctx, func(conn executor) error {
+		applied, err := migrator.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		return migrator.migrate(ctx, conn, applied, version)
+	})
+}
+
+func (migrator *Migrator) migrate(ctx context.Context, conn executor, applied []appliedMigration, target int) error {
+	current := 0
+	if len(applied) > 0 {
+		current = applied[len(applied)-1].version
+	}
+
+	isApplied := map[int]bool{}
+	for _, migration := range applied {
+		isApplied[migration.version] = true
+	}
+
+	for _, migration := range migrator.Migrations {
+		if migration.Version <= target && !isApplied[migration.Version] {
+			if migration.Version < current {
+				return fmt.Errorf("migration: version %d is older than the applied version %d", migration.Version, current)
+			}
+
+			err := migrator.run(ctx, conn, migration, true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(applied) - 1; i >= 0 && applied[i].version > target; i-- {
+		err := migrator.run(ctx, conn, *migrator.find(applied[i].version), false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (migrator *Migrator) run(ctx context.Context, conn executor, migration Migration, up bool) (err error) {
+	script, direction := migration.Up, "up"
+	if !up {
+		script, direction = migration.Down, "down"
+		if script == "" {
+			return fmt.Errorf("migration: version %d has no down migration", migration.Version)
+		}
+	}
+
+	// SQLite already runs inside the transaction that holds its lock.
+	if sqlConn, ok := conn.(*sql.Conn); ok && migrator.Dialect.Name() != "sqlite" {
+		tx, err := sqlConn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				_ = tx.Rollback()
+			} else {
+				err = tx.Commit()
+			}
+		}()
+		conn = tx
+	}
+
+	for _, statement := range statements(script) {
+		_, err := conn.ExecContext(ctx, statement)
+		if err != nil {
+			return fmt.Errorf("migration: %d_%s %s: %w", migration.Version, migration.Name, direction, err)
+		}
+	}
+
+	if up {
+		SQL := "INSERT INTO " + tableName + "(version, name, checksum, applied_at) VALUES (?, ?, ?, ?)"
+		_, err = conn.ExecContext(ctx, migrator.Dialect.Rebind(SQL), migration.Version, migration.Name, migration.Checksum, time.Now().UTC())
+	} else {
+		SQL := "DELETE FROM " + tableName + " WHERE version = ?"
+		_, err = conn.ExecContext(ctx, migrator.Dialect.Rebind(SQL), migration.Version)
+	}
+
+	return err
+}
+
+// applied reads the version table and verifies that every applied migration
+// still matches the embedded one it was created from.
+func (migrator *Migrator) applied(ctx context.Context, conn executor) ([]appliedMigration, error) {
+	SQL := "SELECT version, name, checksum, applied_at FROM " + tableName + " ORDER BY version"
+
+	rows, err := conn.QueryContext(ctx, SQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []appliedMigration
+	for rows.Next() {
+		migration := appliedMigration{}
+		err := rows.Scan(&migration.version, &migration.name, &migration.checksum, &migration.appliedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		embedded := migrator.find(migration.version)
+		if embedded == nil {
+			return nil, fmt.Errorf("migration: applied version %d (%s) is unknown to this binary", migration.version, migration.name)
+		}
+		if embedded.Checksum != migration.checksum {
+			return nil, fmt.Errorf("migration: checksum mismatch for version %d (%s); applied migrations must not be edited", migration.version, migration.name)
+		}
+
+		applied = append(applied, migration)
+	}
+
+	return applied, rows.Err()
+}
+
+// appliedIfExists reads the version table without creating it, which only
+// the locked paths do; a database without one has nothing applied.
+func (migrator *Migrator) appliedIfExists(ctx context.Context, conn executor) ([]appliedMigration, error) {
+	var SQL string
+	switch migrator.Dialect.Name() {
+	case "mysql":
+		SQL = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	case "postgres":
+		SQL = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	default:
+		SQL = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	}
+
+	rows, err := conn.QueryContext(ctx, SQL, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var count int
+	if rows.Next() {
+		err = rows.Scan(&count)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = rows.Err()
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	// The rows must be closed before the connection runs another query.
+	_ = rows.Close()
+
+	return migrator.applied(ctx, conn)
+}
+
+func (migrator *Migrator) find(version int) *Migration {
+	for i := range migrator.Migrations {
+		if migrator.Migrations[i].Version == version {
+			return &migrator.Migrations[i]
+		}
+	}
+
+	return nil
+}
+
+// withLock runs fn on a dedicated connection while holding a database-wide
+// lock, so replicas starting at the same time migrate one after another.
+func (migrator *Migrator) withLock(ctx context.Context, fn func(conn executor) error) (err error) {
+	return migrator.withConn(ctx, true, fn)
+}
+
+func (migrator *Migrator) withConn(ctx context.Context, exclusive bool, fn func(conn executor) error) (err error) {
+	conn, err := migrator.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if exclusive {
+		var unlock func(err error) error
+		unlock, err = migrator.lock(ctx, conn)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = errors.Join(err, unlock(err))
+		}()
+
+		SQL := "CREATE TABLE IF NOT EXISTS " + tableName + " (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, checksum CHAR(64) NOT NULL, applied_at TIMESTAMP NOT NULL)"
+		_, err = conn.ExecContext(ctx, SQL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return fn(conn)
+}
+
+func (migrator *Migrator) lock(ctx context.Context, conn *sql.Conn) (func(err error) error, error) {
+	switch migrator.Dialect.Name() {
+	case "mysql":
+		var acquired sql.NullInt64
+		err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(migrator.LockTimeout.Seconds())).Scan(&acquired)
+		if err != nil {
+			return nil, err
+		}
+		if acquired.Int64 != 1 {
+			return nil, fmt.Errorf("migration: timed out waiting for lock %s", lockName)
+		}
+
+		return func(error) error {
+			_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+			return err
+		}, nil
+	case "postgres":
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(lockName))
+		key := int64(hash.Sum64())
+
+		lockCtx, cancel := context.WithTimeout(ctx, migrator.LockTimeout)
+		defer cancel()
+
+		_, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", key)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(error) error {
+			_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+			return err
+		}, nil
+	default:
+		// SQLite has no advisory locks; an immediate transaction holds the
+		// database write lock instead, and the whole run commits atomically.
+		_, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+		if err != nil {
+			return nil, err
+		}
+
+		return func(err error) error {
+			if err != nil {
+				_, errRollback := conn.ExecContext(context.Background(), "ROLLBACK")
+				return errRollback
+			}
+
+			_, errCommit := conn.ExecContext(context.Background(), "COMMIT")
+			return errCommit
+		}, nil
+	}
+}
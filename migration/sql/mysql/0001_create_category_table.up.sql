@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS category
+(
+    id   INT          NOT NULL AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
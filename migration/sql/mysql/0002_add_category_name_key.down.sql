@@ -0,0 +1,2 @@
+DROP INDEX category_name_key ON category;
+ALTER TABLE category DROP COLUMN name_key;
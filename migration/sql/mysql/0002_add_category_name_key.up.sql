@@ -0,0 +1,5 @@
+-- name_key holds the name as compared by the uniqueness rule; it is NULL
+-- when names need not be unique. The binary collation leaves folding to the
+-- application.
+ALTER TABLE category ADD COLUMN name_key VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NULL;
+CREATE UNIQUE INDEX category_name_key ON category (name_key);
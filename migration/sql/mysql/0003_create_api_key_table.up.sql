@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS api_key
+(
+    id           INT          NOT NULL AUTO_INCREMENT,
+    name         VARCHAR(100) NOT NULL,
+    owner        VARCHAR(100) NOT NULL,
+    prefix       CHAR(16)     NOT NULL,
+    secret_hash  CHAR(64)     NOT NULL,
+    scopes       VARCHAR(255) NOT NULL,
+    created_at   DATETIME     NOT NULL,
+    expires_at   DATETIME     NULL,
+    last_used_at DATETIME     NULL,
+    revoked      BOOLEAN      NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id),
+    UNIQUE KEY api_key_prefix (prefix)
+) ENGINE = InnoDB;
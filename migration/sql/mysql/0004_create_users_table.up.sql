@@ -0,0 +1,30 @@
+-- Usernames are lowercased by the application, so a binary comparison is
+-- enough to keep them unique.
+CREATE TABLE IF NOT EXISTS users
+(
+    id                  INT          NOT NULL AUTO_INCREMENT,
+    username            VARCHAR(64)  CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    password_hash       VARCHAR(100) NOT NULL,
+    scopes              VARCHAR(255) NOT NULL,
+    failed_logins       INT          NOT NULL DEFAULT 0,
+    locked_until        DATETIME     NULL,
+    created_at          DATETIME     NOT NULL,
+    password_changed_at DATETIME     NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY users_username (username)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS user_session
+(
+    id          INT      NOT NULL AUTO_INCREMENT,
+    user_id     INT      NOT NULL,
+    prefix      CHAR(16) NOT NULL,
+    secret_hash CHAR(64) NOT NULL,
+    created_at  DATETIME NOT NULL,
+    expires_at  DATETIME NOT NULL,
+    revoked     BOOLEAN  NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id),
+    UNIQUE KEY user_session_prefix (prefix),
+    KEY user_session_user_id (user_id),
+    CONSTRAINT user_session_user FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE = InnoDB;
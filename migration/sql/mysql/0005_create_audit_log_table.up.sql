@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id          INT          NOT NULL AUTO_INCREMENT,
+    occurred_at DATETIME(6)  NOT NULL,
+    actor       VARCHAR(255) NOT NULL,
+    actor_name  VARCHAR(255) NOT NULL,
+    action      VARCHAR(64)  NOT NULL,
+    entity_type VARCHAR(64)  NOT NULL,
+    entity_id   INT          NOT NULL,
+    before_json TEXT         NULL,
+    after_json  TEXT         NULL,
+    request_id  VARCHAR(128) NOT NULL,
+    client_ip   VARCHAR(64)  NOT NULL,
+    PRIMARY KEY (id),
+    KEY audit_log_entity (entity_type, entity_id),
+    KEY audit_log_actor (actor),
+    KEY audit_log_occurred_at (occurred_at)
+) ENGINE = InnoDB;
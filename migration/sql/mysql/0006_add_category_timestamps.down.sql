@@ -0,0 +1,5 @@
+DELETE FROM category WHERE deleted_at IS NOT NULL;
+DROP INDEX category_deleted_at ON category;
+ALTER TABLE category DROP COLUMN deleted_at;
+ALTER TABLE category DROP COLUMN updated_at;
+ALTER TABLE category DROP COLUMN created_at;
@@ -0,0 +1,7 @@
+-- deleted_at marks a soft-deleted category. Deleting also clears name_key,
+-- so the unique index only covers the categories in use.
+ALTER TABLE category ADD COLUMN created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
+ALTER TABLE category ADD COLUMN updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
+ALTER TABLE category ADD COLUMN deleted_at DATETIME(6) NULL;
+UPDATE category SET created_at = UTC_TIMESTAMP(6), updated_at = UTC_TIMESTAMP(6);
+CREATE INDEX category_deleted_at ON category (deleted_at);
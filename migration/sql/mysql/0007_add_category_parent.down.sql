@@ -0,0 +1,3 @@
+ALTER TABLE category DROP FOREIGN KEY category_parent;
+DROP INDEX category_parent_id ON category;
+ALTER TABLE category DROP COLUMN parent_id;
@@ -0,0 +1,5 @@
+-- parent_id makes categories a tree; root categories have none. Children
+-- must be moved or removed before their parent can be purged.
+ALTER TABLE category ADD COLUMN parent_id INT NULL;
+CREATE INDEX category_parent_id ON category (parent_id);
+ALTER TABLE category ADD CONSTRAINT category_parent FOREIGN KEY (parent_id) REFERENCES category (id);
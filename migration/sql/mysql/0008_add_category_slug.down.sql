@@ -0,0 +1,3 @@
+DROP TABLE category_old_slug;
+DROP INDEX category_slug ON category;
+ALTER TABLE category DROP COLUMN slug;
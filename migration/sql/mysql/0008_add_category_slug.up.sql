@@ -0,0 +1,15 @@
+-- slug names a category in URLs. It is NULL for categories created before
+-- slugs until "categories reindex" generates them. category_old_slug keeps
+-- the slugs a category had before, so that old URLs can be redirected; no
+-- other category may take them.
+ALTER TABLE category ADD COLUMN slug VARCHAR(255) CHARACTER SET ascii COLLATE ascii_bin NULL;
+CREATE UNIQUE INDEX category_slug ON category (slug);
+CREATE TABLE IF NOT EXISTS category_old_slug
+(
+    slug        VARCHAR(255) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    category_id INT          NOT NULL,
+    created_at  DATETIME(6)  NOT NULL,
+    PRIMARY KEY (slug),
+    KEY category_old_slug_category_id (category_id),
+    CONSTRAINT category_old_slug_category FOREIGN KEY (category_id) REFERENCES category (id)
+) ENGINE = InnoDB;
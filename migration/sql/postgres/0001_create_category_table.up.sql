@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS category
+(
+    id   SERIAL       PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
@@ -0,0 +1,4 @@
+-- name_key holds the name as compared by the uniqueness rule; it is NULL
+-- when names need not be unique.
+ALTER TABLE category ADD COLUMN name_key VARCHAR(255) NULL;
+CREATE UNIQUE INDEX category_name_key ON category (name_key);
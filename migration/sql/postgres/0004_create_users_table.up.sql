@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id                  SERIAL       PRIMARY KEY,
+    username            VARCHAR(64)  NOT NULL,
+    password_hash       VARCHAR(100) NOT NULL,
+    scopes              VARCHAR(255) NOT NULL,
+    failed_logins       INTEGER      NOT NULL DEFAULT 0,
+    locked_until        TIMESTAMP    NULL,
+    created_at          TIMESTAMP    NOT NULL,
+    password_changed_at TIMESTAMP    NOT NULL
+);
+CREATE UNIQUE INDEX users_username ON users (username);
+
+CREATE TABLE IF NOT EXISTS user_session
+(
+    id          SERIAL    PRIMARY KEY,
+    user_id     INTEGER   NOT NULL REFERENCES users (id),
+    prefix      CHAR(16)  NOT NULL,
+    secret_hash CHAR(64)  NOT NULL,
+    created_at  TIMESTAMP NOT NULL,
+    expires_at  TIMESTAMP NOT NULL,
+    revoked     BOOLEAN   NOT NULL DEFAULT FALSE
+);
+CREATE UNIQUE INDEX user_session_prefix ON user_session (prefix);
+CREATE INDEX user_session_user_id ON user_session (user_id);
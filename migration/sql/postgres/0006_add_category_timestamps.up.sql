@@ -0,0 +1,6 @@
+-- deleted_at marks a soft-deleted category. Deleting also clears name_key,
+-- so the unique index only covers the categories in use.
+ALTER TABLE category ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc');
+ALTER TABLE category ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc');
+ALTER TABLE category ADD COLUMN deleted_at TIMESTAMP NULL;
+CREATE INDEX category_deleted_at ON category (deleted_at);
@@ -0,0 +1,4 @@
+-- parent_id makes categories a tree; root categories have none. Children
+-- must be moved or removed before their parent can be purged.
+ALTER TABLE category ADD COLUMN parent_id INTEGER NULL CONSTRAINT category_parent REFERENCES category (id);
+CREATE INDEX category_parent_id ON category (parent_id);
@@ -0,0 +1,13 @@
+-- slug names a category in URLs. It is NULL for categories created before
+-- slugs until "categories reindex" generates them. category_old_slug keeps
+-- the slugs a category had before, so that old URLs can be redirected; no
+-- other category may take them.
+ALTER TABLE category ADD COLUMN slug VARCHAR(255) NULL;
+CREATE UNIQUE INDEX category_slug ON category (slug);
+CREATE TABLE IF NOT EXISTS category_old_slug
+(
+    slug        VARCHAR(255) PRIMARY KEY,
+    category_id INTEGER      NOT NULL REFERENCES category (id),
+    created_at  TIMESTAMP    NOT NULL
+);
+CREATE INDEX category_old_slug_category_id ON category_old_slug (category_id);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id          INTEGER      PRIMARY KEY AUTOINCREMENT,
+    occurred_at DATETIME     NOT NULL,
+    actor       VARCHAR(255) NOT NULL,
+    actor_name  VARCHAR(255) NOT NULL,
+    action      VARCHAR(64)  NOT NULL,
+    entity_type VARCHAR(64)  NOT NULL,
+    entity_id   INTEGER      NOT NULL,
+    before_json TEXT         NULL,
+    after_json  TEXT         NULL,
+    request_id  VARCHAR(128) NOT NULL,
+    client_ip   VARCHAR(64)  NOT NULL
+);
+CREATE INDEX audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX audit_log_actor ON audit_log (actor);
+CREATE INDEX audit_log_occurred_at ON audit_log (occurred_at);
@@ -0,0 +1,8 @@
+-- deleted_at marks a soft-deleted category. Deleting also clears name_key,
+-- so the unique index only covers the categories in use. SQLite cannot add
+-- a column defaulting to the current time, so existing rows are updated.
+ALTER TABLE category ADD COLUMN created_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE category ADD COLUMN updated_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE category ADD COLUMN deleted_at DATETIME NULL;
+UPDATE category SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP;
+CREATE INDEX category_deleted_at ON category (deleted_at);
@@ -0,0 +1,5 @@
+-- parent_id makes categories a tree; root categories have none. Children
+-- must be moved or removed before their parent can be purged. SQLite only
+-- enforces the reference with foreign keys enabled, which NewDB does.
+ALTER TABLE category ADD COLUMN parent_id INTEGER NULL REFERENCES category (id);
+CREATE INDEX category_parent_id ON category (parent_id);
@@ -0,0 +1,3 @@
+DROP TABLE category_old_slug;
+DROP INDEX category_slug;
+ALTER TABLE category DROP COLUMN slug;
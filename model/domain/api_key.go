@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// ApiKey is an issued key. Only a hash of the secret is kept; the prefix
+// identifies the key without revealing it. Zero times mean "never".
+type ApiKey struct {
+	Id         int
+	Name       string
+	Owner      string
+	Prefix     string
+	SecretHash string
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	Revoked    bool
+}
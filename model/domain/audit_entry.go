@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// AuditEntry records one change to an entity. Before and After hold the
+// JSON of the entity and are empty when it did not exist.
+type AuditEntry struct {
+	Id         int
+	OccurredAt time.Time
+	// Actor is "<identity type>:<subject>", e.g. "api_key:12".
+	Actor      string
+	ActorName  string
+	Action     string
+	EntityType string
+	EntityId   int
+	Before     string
+	After      string
+	RequestId  string
+	ClientIp   string
+}
+
+// AuditFilter selects audit entries; zero fields do not filter.
+type AuditFilter struct {
+	Actor      string
+	EntityType string
+	EntityId   int
+	Action     string
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
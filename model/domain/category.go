@@ -1,6 +1,46 @@
 package domain
 
+import "time"
+
 type Category struct {
-	Id   int
-	Name string
+	Id int
+	// ParentId is 0 for a root category.
+	ParentId int
+	Name     string
+	// Slug names the category in URLs; it is "" for categories created
+	// before slugs until they are reindexed.
+	Slug string
+	// NameKey is the name as compared by the uniqueness rule, or "" when
+	// names need not be unique or the category is deleted.
+	NameKey   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is set once the category is soft-deleted.
+	DeletedAt time.Time
+}
+
+// CategorySortFields are the fields categories can be sorted by.
+var CategorySortFields = []string{"id", "name"}
+
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// CategoryQuery selects a page of categories. Zero filters do not filter.
+// Sort ends with a unique field so that After and Before, the categories
+// of a cursor, name an exact position; Offset is only used without them.
+type CategoryQuery struct {
+	Name         string
+	NamePrefix   string
+	NameContains string
+	MinId        int
+	MaxId        int
+	// IncludeDeleted also selects soft-deleted categories.
+	IncludeDeleted bool
+	Sort           []SortField
+	After          *Category
+	Before         *Category
+	Offset         int
+	Limit          int
 }
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// User is a person who logs in with a password. LockedUntil is zero unless
+// too many logins failed in a row.
+type User struct {
+	Id                int
+	Username          string
+	PasswordHash      string
+	Scopes            []string
+	FailedLogins      int
+	LockedUntil       time.Time
+	CreatedAt         time.Time
+	PasswordChangedAt time.Time
+}
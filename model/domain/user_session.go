@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// UserSession is one login. Like an ApiKey, it is looked up by its prefix
+// and only a hash of the secret is kept; sessions handed out as JWTs carry
+// the prefix as their "sid" claim and have no secret.
+type UserSession struct {
+	Id         int
+	UserId     int
+	Prefix     string
+	SecretHash string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	Revoked    bool
+}
@@ -0,0 +1,11 @@
+package web
+
+import "time"
+
+// ApiKeyCreateRequest scopes must be a subset of auth.Scopes.
+type ApiKeyCreateRequest struct {
+	Name      string     `validate:"required,max=100,min=1" json:"name"`
+	Owner     string     `validate:"required,max=100,min=1" json:"owner"`
+	Scopes    []string   `validate:"required,min=1,dive,oneof=categories:read categories:write categories:delete admin" json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,14 @@
+package web
+
+import "time"
+
+// AuditQuery filters GET /api/audit; the json names are those of the query
+// parameters. From is inclusive and To exclusive.
+type AuditQuery struct {
+	Actor    string     `validate:"max=255" json:"actor"`
+	EntityId int        `validate:"min=0" json:"entity_id"`
+	Action   string     `validate:"omitempty,oneof=category.create category.update category.delete category.restore category.purge category.move" json:"action"`
+	From     *time.Time `json:"from"`
+	To       *time.Time `json:"to"`
+	Limit    int        `validate:"min=0,max=1000" json:"limit"`
+}
@@ -1,10 +1,51 @@
 package web
 
+// CategoryCreateRequest creates a root category unless ParentId is set.
+// Slug is generated from Name unless given.
 type CategoryCreateRequest struct {
-	Name string `validate:"required,max=255,min=1" json:"name"`
+	Name     string `validate:"required,max=255,min=1" json:"name"`
+	ParentId int    `validate:"min=0" json:"parent_id"`
+	Slug     string `validate:"max=100" json:"slug"`
 }
 
+// CategoryUpdateRequest renames a category. Unless Slug is given, a new
+// name also generates a new slug; the old one keeps redirecting.
 type CategoryUpdateRequest struct {
 	Id   int    `validate:"required" json:"id"`
 	Name string `validate:"required,max=255,min=1" json:"name"`
+	Slug string `validate:"max=100" json:"slug"`
+}
+
+// CategoryMoveRequest moves a category and its subtree under ParentId, or
+// to the root when ParentId is 0.
+type CategoryMoveRequest struct {
+	Id       int `validate:"required" json:"id"`
+	ParentId int `validate:"min=0" json:"parent_id"`
+}
+
+// CategoryDeleteRequest deletes a category. Children is what happens to its
+// child categories: reject the delete, cascade it to the whole subtree, or
+// reparent them to the parent of the category. "" uses the configured
+// policy.
+type CategoryDeleteRequest struct {
+	Id       int    `validate:"required" json:"id"`
+	Children string `validate:"omitempty,oneof=reject cascade reparent" json:"children"`
+}
+
+// CategoryListRequest filters and pages GET /api/categories; the json names
+// are those of the query parameters. Name matches exactly, NamePrefix and
+// NameContains ignore case. IncludeDeleted, for admins, also lists
+// soft-deleted categories. Sort lists fields, descending when prefixed with
+// "-". Limit 0 uses the default page size.
+type CategoryListRequest struct {
+	Name           string `validate:"max=255" json:"name"`
+	NamePrefix     string `validate:"max=255" json:"name_prefix"`
+	NameContains   string `validate:"max=255" json:"name_contains"`
+	MinId          int    `validate:"min=0" json:"min_id"`
+	MaxId          int    `validate:"min=0" json:"max_id"`
+	IncludeDeleted bool   `json:"include_deleted"`
+	Sort           string `validate:"max=100" json:"sort"`
+	Limit          int    `validate:"min=0" json:"limit"`
+	Offset         int    `validate:"min=0" json:"offset"`
+	Cursor         string `json:"cursor"`
 }
@@ -1,6 +1,24 @@
 package web
 
+import "time"
+
 type CategoryResponse struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id        int        `json:"id"`
+	ParentId  *int       `json:"parent_id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+type CategoryPageResponse struct {
+	Categories []CategoryResponse
+	Page       PageResponse
+}
+
+// CategoryTreeResponse is a category with its subtree nested below it.
+type CategoryTreeResponse struct {
+	CategoryResponse
+	Children []CategoryTreeResponse `json:"children"`
 }
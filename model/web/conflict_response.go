@@ -0,0 +1,6 @@
+package web
+
+type ConflictResponse struct {
+	Message     string      `json:"message"`
+	Conflicting interface{} `json:"conflicting,omitempty"`
+}
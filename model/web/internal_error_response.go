@@ -0,0 +1,6 @@
+package web
+
+type InternalErrorResponse struct {
+	ErrorId string `json:"error_id"`
+	Message string `json:"message"`
+}
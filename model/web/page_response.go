@@ -0,0 +1,10 @@
+package web
+
+// PageResponse describes the page of a list response. A cursor is only set
+// when there are items in that direction.
+type PageResponse struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
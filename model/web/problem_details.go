@@ -0,0 +1,14 @@
+package web
+
+// ProblemDetails is an RFC 7807 error document.
+type ProblemDetails struct {
+	Type     string               `json:"type"`
+	Title    string               `json:"title"`
+	Status   int                  `json:"status"`
+	Detail   string               `json:"detail,omitempty"`
+	Instance string               `json:"instance,omitempty"`
+	Errors   []FieldErrorResponse `json:"errors,omitempty"`
+	ErrorId  string               `json:"error_id,omitempty"`
+	// Conflicting is the existing resource a 409 collided with.
+	Conflicting interface{} `json:"conflicting,omitempty"`
+}
@@ -0,0 +1,26 @@
+package web
+
+// UserCreateRequest registers a user. Usernames are compared in lower case;
+// passwords are limited to 72 bytes by bcrypt.
+type UserCreateRequest struct {
+	Username string   `validate:"required,max=64,min=3" json:"username"`
+	Password string   `validate:"required,max=72,min=10" json:"password"`
+	Scopes   []string `validate:"dive,oneof=categories:read categories:write categories:delete admin" json:"scopes"`
+}
+
+// RegisterRequest is a UserCreateRequest without scopes, which
+// self-registered users do not choose.
+type RegisterRequest struct {
+	Username string `validate:"required,max=64,min=3" json:"username"`
+	Password string `validate:"required,max=72,min=10" json:"password"`
+}
+
+type LoginRequest struct {
+	Username string `validate:"required" json:"username"`
+	Password string `validate:"required" json:"password"`
+}
+
+type PasswordChangeRequest struct {
+	CurrentPassword string `validate:"required" json:"current_password"`
+	NewPassword     string `validate:"required,max=72,min=10" json:"new_password"`
+}
@@ -0,0 +1,20 @@
+package web
+
+import "time"
+
+type UserResponse struct {
+	Id                int        `json:"id"`
+	Username          string     `json:"username"`
+	Scopes            []string   `json:"scopes"`
+	CreatedAt         time.Time  `json:"created_at"`
+	PasswordChangedAt time.Time  `json:"password_changed_at"`
+	LockedUntil       *time.Time `json:"locked_until"`
+}
+
+// LoginResponse carries the bearer token of a new session.
+type LoginResponse struct {
+	Token     string       `json:"token"`
+	TokenType string       `json:"token_type"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      UserResponse `json:"user"`
+}
@@ -4,4 +4,6 @@ type WebResponse struct {
 	Code   int         `json:"code"`
 	Status string      `json:"status"`
 	Data   interface{} `json:"data"`
+	// Page is set by list endpoints that return one page at a time.
+	Page *PageResponse `json:"page,omitempty"`
 }
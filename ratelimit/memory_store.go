@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped. A bucket that has
+// refilled completely is no different from a new one.
+const sweepInterval = time.Minute
+
+type MemoryStore struct {
+	// Now is the clock, replaceable in tests.
+	Now func() time.Time
+
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{Now: time.Now, buckets: map[string]*bucket{}}
+}
+
+func (store *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := store.Now()
+	capacity := limit.capacity()
+	rate := limit.rate()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.sweep(now)
+
+	current, ok := store.buckets[key]
+	if !ok {
+		current = &bucket{tokens: capacity, updated: now}
+		store.buckets[key] = current
+	} else if now.After(current.updated) {
+		current.tokens = math.Min(capacity, current.tokens+now.Sub(current.updated).Seconds()*rate)
+		current.updated = now
+	}
+
+	result := Result{Limit: int(capacity)}
+	if current.tokens >= 1 {
+		current.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - current.tokens) / rate)
+	}
+
+	result.Remaining = int(current.tokens)
+	result.Reset = seconds((capacity - current.tokens) / rate)
+	current.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+func (store *MemoryStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < sweepInterval {
+		return
+	}
+	store.lastSweep = now
+
+	for key, idle := range store.buckets {
+		if !now.Before(idle.full) {
+			delete(store.buckets, key)
+		}
+	}
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket: Requests per Per on average, in bursts of up to
+// Burst requests. Burst defaults to Requests.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (limit Limit) capacity() float64 {
+	if limit.Burst > 0 {
+		return float64(limit.Burst)
+	}
+
+	return float64(limit.Requests)
+}
+
+// rate is the number of tokens added per second.
+func (limit Limit) rate() float64 {
+	return float64(limit.Requests) / limit.Per.Seconds()
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until a request would be allowed, zero when
+	// this one was.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. MemoryStore serves a single instance; a store
+// shared between instances only has to implement Take atomically.
+type Store interface {
+	// Take removes a token from the bucket named key, creating it full.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Rules decides which buckets a request draws from: one per client, and one
+// per client and route for the routes listed in Routes.
+type Rules struct {
+	Default Limit
+	// Keys overrides Default for single clients, e.g. "api_key:12".
+	Keys map[string]Limit
+	// Routes is keyed "METHOD /pattern" like auth.Policy.
+	Routes map[string]Limit
+}
+
+func (rules Rules) ClientLimit(client string) Limit {
+	if limit, ok := rules.Keys[client]; ok {
+		return limit
+	}
+
+	return rules.Default
+}
+
+// RouteLimit finds the route limit matching the request, reporting the
+// route pattern it was configured for. Like the router, it prefers static
+// segments, so /api/categories/tree is not /api/categories/:categoryId.
+func (rules Rules) RouteLimit(method string, path string) (string, Limit, bool) {
+	found, wildcards := "", 0
+	for route := range rules.Routes {
+		routeMethod, pattern, _ := strings.Cut(route, " ")
+		if routeMethod != method || !matchPattern(pattern, path) {
+			continue
+		}
+		count := strings.Count(pattern, ":") + strings.Count(pattern, "*")
+		if found == "" || count < wildcards || (count == wildcards && route < found) {
+			found, wildcards = route, count
+		}
+	}
+	if found == "" {
+		return "", Limit{}, false
+	}
+
+	return found, rules.Routes[found], true
+}
+
+// matchPattern matches path against an httprouter pattern, where ":name"
+// stands for one segment and "*name" for the rest of the path.
+func matchPattern(pattern string, path string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return len(patternSegments) == len(pathSegments)
+}
@@ -0,0 +1,44 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FileReporter appends one JSON document per report to a file.
+type FileReporter struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func NewFileReporter(path string) (*FileReporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileReporter{file: file}, nil
+}
+
+func (reporter *FileReporter) Report(ctx context.Context, report Report) error {
+	line, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	reporter.mutex.Lock()
+	defer reporter.mutex.Unlock()
+
+	_, err = reporter.file.Write(append(line, '\n'))
+
+	return err
+}
+
+func (reporter *FileReporter) Close() error {
+	reporter.mutex.Lock()
+	defer reporter.mutex.Unlock()
+
+	return reporter.file.Close()
+}
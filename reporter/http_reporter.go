@@ -0,0 +1,51 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPReporter posts each report as JSON to a collector.
+type HTTPReporter struct {
+	URL     string
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+func NewHTTPReporter(url string, timeout time.Duration) *HTTPReporter {
+	return &HTTPReporter{URL: url, Client: http.DefaultClient, Timeout: timeout}
+}
+
+func (reporter *HTTPReporter) Report(ctx context.Context, report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	// The request that failed may already be cancelled; the report should
+	// still be delivered.
+	ctx, cancel := context.WithTimeout(context.Background(), reporter.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, reporter.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := reporter.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("reporter: collector responded %s", response.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package reporter
+
+import (
+	"context"
+	"io"
+	"log"
+)
+
+type LogReporter struct {
+	Logger *log.Logger
+}
+
+func NewLogReporter(writer io.Writer) *LogReporter {
+	return &LogReporter{Logger: log.New(writer, "", log.LstdFlags)}
+}
+
+func (reporter *LogReporter) Report(ctx context.Context, report Report) error {
+	reporter.Logger.Printf("error %s: %s %s (request %s): %s\n%s", report.ErrorId, report.Method, report.Path, report.RequestId, report.Error, report.Stack)
+
+	return nil
+}
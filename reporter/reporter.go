@@ -0,0 +1,41 @@
+package reporter
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Report is everything support needs to find an internal error that a
+// client only knows by its error ID.
+type Report struct {
+	ErrorId   string    `json:"error_id"`
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	RequestId string    `json:"request_id,omitempty"`
+	Error     string    `json:"error"`
+	Stack     string    `json:"stack,omitempty"`
+}
+
+type Reporter interface {
+	Report(ctx context.Context, report Report) error
+}
+
+type contextKey struct{}
+
+var defaultReporter = NewLogReporter(os.Stderr)
+
+func WithReporter(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, contextKey{}, reporter)
+}
+
+// FromContext falls back to logging to stderr, so no error goes unreported.
+func FromContext(ctx context.Context) Reporter {
+	reporter, ok := ctx.Value(contextKey{}).(Reporter)
+	if !ok {
+		return defaultReporter
+	}
+
+	return reporter
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"golang-restful-api/model/domain"
+	"time"
+)
+
+type ApiKeyRepository interface {
+	Save(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error)
+	Update(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error)
+	UpdateLastUsed(ctx context.Context, tx Tx, apiKey domain.ApiKey, usedAt time.Time) error
+	FindById(ctx context.Context, tx Tx, apiKeyId int) (domain.ApiKey, error)
+	FindByIdForUpdate(ctx context.Context, tx Tx, apiKeyId int) (domain.ApiKey, error)
+	FindByPrefix(ctx context.Context, tx Tx, prefix string) (domain.ApiKey, error)
+	FindAll(ctx context.Context, tx Tx) ([]domain.ApiKey, error)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/domain"
+	"strings"
+	"time"
+)
+
+const apiKeyColumns = "id, name, owner, prefix, secret_hash, scopes, created_at, expires_at, last_used_at, revoked"
+
+type ApiKeyRepositoryImplementation struct {
+	Dialect Dialect
+}
+
+func NewApiKeyRepository(dialect Dialect) ApiKeyRepository {
+	return &ApiKeyRepositoryImplementation{Dialect: dialect}
+}
+
+func (repository *ApiKeyRepositoryImplementation) Save(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error) {
+	SQL := "INSERT INTO api_key(name, owner, prefix, secret_hash, scopes, created_at, expires_at, last_used_at, revoked) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	id, err := repository.Dialect.InsertReturningId(ctx, sqlTx(tx), SQL,
+		apiKey.Name, apiKey.Owner, apiKey.Prefix, apiKey.SecretHash, strings.Join(apiKey.Scopes, " "),
+		apiKey.CreatedAt, nullTime(apiKey.ExpiresAt), nullTime(apiKey.LastUsedAt), apiKey.Revoked)
+	if err != nil {
+		return apiKey, translateError(err)
+	}
+
+	apiKey.Id = int(id)
+	return apiKey, nil
+}
+
+func (repository *ApiKeyRepositoryImplementation) Update(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error) {
+	SQL := "UPDATE api_key SET name = ?, owner = ?, prefix = ?, secret_hash = ?, scopes = ?, expires_at = ?, last_used_at = ?, revoked = ? WHERE id = ?"
+
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL),
+		apiKey.Name, apiKey.Owner, apiKey.Prefix, apiKey.SecretHash, strings.Join(apiKey.Scopes, " "),
+		nullTime(apiKey.ExpiresAt), nullTime(apiKey.LastUsedAt), apiKey.Revoked, apiKey.Id)
+	if err != nil {
+		return apiKey, translateError(err)
+	}
+
+	return apiKey, nil
+}
+
+// UpdateLastUsed only touches last_used_at, and only while the key is still
+// the one that was verified: not revoked and not rotated since.
+func (repository *ApiKeyRepositoryImplementation) UpdateLastUsed(ctx context.Context, tx Tx, apiKey domain.ApiKey, usedAt time.Time) error {
+	SQL := "UPDATE api_key SET last_used_at = ? WHERE id = ? AND prefix = ? AND revoked = false"
+
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), nullTime(usedAt), apiKey.Id, apiKey.Prefix)
+	return translateError(err)
+}
+
+func (repository *ApiKeyRepositoryImplementation) FindById(ctx context.Context, tx Tx, apiKeyId int) (domain.ApiKey, error) {
+	SQL := "SELECT " + apiKeyColumns + " FROM api_key WHERE id = ?"
+
+	return repository.findOne(ctx, tx, SQL, apiKeyId)
+}
+
+func (repository *ApiKeyRepositoryImplementation) FindByIdForUpdate(ctx context.Context, tx Tx, apiKeyId int) (domain.ApiKey, error) {
+	SQL := "SELECT " + apiKeyColumns + " FROM api_key WHERE id = ?"
+
+	return repository.findOne(ctx, tx, repository.Dialect.ForUpdate(SQL), apiKeyId)
+}
+
+func (repository *ApiKeyRepositoryImplementation) FindByPrefix(ctx context.Context, tx Tx, prefix string) (domain.ApiKey, error) {
+	SQL := "SELECT " + apiKeyColumns + " FROM api_key WHERE prefix = ?"
+
+	return repository.findOne(ctx, tx, SQL, prefix)
+}
+
+func (repository *ApiKeyRepositoryImplementation) FindAll(ctx context.Context, tx Tx) ([]domain.ApiKey, error) {
+	SQL := "SELECT " + apiKeyColumns + " FROM api_key ORDER BY id"
+
+	rows, err := sqlTx(tx).QueryContext(ctx, SQL)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	var apiKeys []domain.ApiKey
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, translateError(rows.Err())
+}
+
+func (repository *ApiKeyRepositoryImplementation) findOne(ctx context.Context, tx Tx, SQL string, args ...interface{}) (domain.ApiKey, error) {
+	rows, err := sqlTx(tx).QueryContext(ctx, repository.Dialect.Rebind(SQL), args...)
+	if err != nil {
+		return domain.ApiKey{}, translateError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return apiKey, translateError(err)
+		}
+		return apiKey, nil
+	} else if rows.Err() != nil {
+		return domain.ApiKey{}, translateError(rows.Err())
+	} else {
+		return domain.ApiKey{}, exception.NewNotFoundError("api key not found")
+	}
+}
+
+func scanApiKey(rows *sql.Rows) (domain.ApiKey, error) {
+	apiKey := domain.ApiKey{}
+	var scopes string
+	var expiresAt, lastUsedAt sql.NullTime
+
+	err := rows.Scan(&apiKey.Id, &apiKey.Name, &apiKey.Owner, &apiKey.Prefix, &apiKey.SecretHash, &scopes,
+		&apiKey.CreatedAt, &expiresAt, &lastUsedAt, &apiKey.Revoked)
+
+	apiKey.Scopes = strings.Fields(scopes)
+	apiKey.CreatedAt = apiKey.CreatedAt.UTC()
+	apiKey.ExpiresAt = expiresAt.Time.UTC()
+	apiKey.LastUsedAt = lastUsedAt.Time.UTC()
+
+	return apiKey, err
+}
+
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value.UTC(), Valid: !value.IsZero()}
+}
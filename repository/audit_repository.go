@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"golang-restful-api/model/domain"
+)
+
+type AuditRepository interface {
+	Save(ctx context.Context, tx Tx, entry domain.AuditEntry) (domain.AuditEntry, error)
+	// FindAll returns the newest entries first.
+	FindAll(ctx context.Context, tx Tx, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang-restful-api/model/domain"
+	"strconv"
+	"strings"
+)
+
+const auditColumns = "id, occurred_at, actor, actor_name, action, entity_type, entity_id, before_json, after_json, request_id, client_ip"
+
+type AuditRepositoryImplementation struct {
+	Dialect Dialect
+}
+
+func NewAuditRepository(dialect Dialect) AuditRepository {
+	return &AuditRepositoryImplementation{Dialect: dialect}
+}
+
+func (repository *AuditRepositoryImplementation) Save(ctx context.Context, tx Tx, entry domain.AuditEntry) (domain.AuditEntry, error) {
+	SQL := "INSERT INTO audit_log(occurred_at, actor, actor_name, action, entity_type, entity_id, before_json, after_json, request_id, client_ip) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	id, err := repository.Dialect.InsertReturningId(ctx, sqlTx(tx), SQL,
+		entry.OccurredAt.UTC(), entry.Actor, entry.ActorName, entry.Action, entry.EntityType, entry.EntityId,
+		nullString(entry.Before), nullString(entry.After), entry.RequestId, entry.ClientIp)
+	if err != nil {
+		return entry, translateError(err)
+	}
+
+	entry.Id = int(id)
+	return entry, nil
+}
+
+func (repository *AuditRepositoryImplementation) FindAll(ctx context.Context, tx Tx, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityId != 0 {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, filter.EntityId)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "occurred_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "occurred_at < ?")
+		args = append(args, filter.To.UTC())
+	}
+
+	SQL := "SELECT " + auditColumns + " FROM audit_log"
+	if len(conditions) > 0 {
+		SQL += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	SQL += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		SQL += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := sqlTx(tx).QueryContext(ctx, repository.Dialect.Rebind(SQL), args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, translateError(rows.Err())
+}
+
+func scanAuditEntry(rows *sql.Rows) (domain.AuditEntry, error) {
+	entry := domain.AuditEntry{}
+	var before, after sql.NullString
+
+	err := rows.Scan(&entry.Id, &entry.OccurredAt, &entry.Actor, &entry.ActorName, &entry.Action, &entry.EntityType,
+		&entry.EntityId, &before, &after, &entry.RequestId, &entry.ClientIp)
+
+	entry.OccurredAt = entry.OccurredAt.UTC()
+	entry.Before = before.String
+	entry.After = after.String
+
+	return entry, err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"golang-restful-api/model/domain"
+	"sort"
+	"sync"
+)
+
+// MemoryAuditRepository keeps audit entries in memory, staged per
+// transaction like MemoryCategoryRepository.
+type MemoryAuditRepository struct {
+	mutex   sync.RWMutex
+	lastId  int
+	entries []domain.AuditEntry
+	pending map[*MemoryTx][]domain.AuditEntry
+}
+
+func NewMemoryAuditRepository() AuditRepository {
+	return &MemoryAuditRepository{pending: map[*MemoryTx][]domain.AuditEntry{}}
+}
+
+func (repository *MemoryAuditRepository) Save(ctx context.Context, tx Tx, entry domain.AuditEntry) (domain.AuditEntry, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.lastId++
+	entry.Id = repository.lastId
+
+	memoryTx := memoryTx(tx)
+	if _, ok := repository.pending[memoryTx]; !ok {
+		memoryTx.OnFinish(nil, func() {
+			repository.mutex.Lock()
+			defer repository.mutex.Unlock()
+
+			repository.entries = append(repository.entries, repository.pending[memoryTx]...)
+			delete(repository.pending, memoryTx)
+		}, func() {
+			repository.mutex.Lock()
+			defer repository.mutex.Unlock()
+
+			delete(repository.pending, memoryTx)
+		})
+	}
+	repository.pending[memoryTx] = append(repository.pending[memoryTx], entry)
+
+	return entry, nil
+}
+
+func (repository *MemoryAuditRepository) FindAll(ctx context.Context, tx Tx, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	var entries []domain.AuditEntry
+	for _, staged := range [][]domain.AuditEntry{repository.entries, repository.pending[memoryTx(tx)]} {
+		for _, entry := range staged {
+			if matchAuditFilter(entry, filter) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Id > entries[j].Id
+	})
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+
+	return entries, nil
+}
+
+func matchAuditFilter(entry domain.AuditEntry, filter domain.AuditFilter) bool {
+	return (filter.Actor == "" || entry.Actor == filter.Actor) &&
+		(filter.EntityType == "" || entry.EntityType == filter.EntityType) &&
+		(filter.EntityId == 0 || entry.EntityId == filter.EntityId) &&
+		(filter.Action == "" || entry.Action == filter.Action) &&
+		(filter.From.IsZero() || !entry.OccurredAt.Before(filter.From)) &&
+		(filter.To.IsZero() || entry.OccurredAt.Before(filter.To))
+}
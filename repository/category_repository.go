@@ -2,14 +2,38 @@ package repository
 
 import (
 	"context"
-	"database/sql"
 	"golang-restful-api/model/domain"
 )
 
+// CategoryRepository hides soft-deleted categories unless a method says
+// otherwise. Update also soft-deletes and restores, and keeps a replaced
+// slug as an old slug of the category; Delete removes the row with its old
+// slugs.
 type CategoryRepository interface {
-	Save(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category
-	Update(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category
-	Delete(ctx context.Context, tx *sql.Tx, category domain.Category)
-	FindById(ctx context.Context, tx *sql.Tx, categoryId int) (domain.Category, error)
-	FindAll(ctx context.Context, tx *sql.Tx) []domain.Category
+	Save(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error)
+	Update(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error)
+	Delete(ctx context.Context, tx Tx, category domain.Category) error
+	FindById(ctx context.Context, tx Tx, categoryId int) (domain.Category, error)
+	FindByIdIncludingDeleted(ctx context.Context, tx Tx, categoryId int) (domain.Category, error)
+	FindByNameKey(ctx context.Context, tx Tx, nameKey string) (domain.Category, error)
+	FindBySlug(ctx context.Context, tx Tx, slug string) (domain.Category, error)
+	// FindByOldSlug finds the category that had slug before.
+	FindByOldSlug(ctx context.Context, tx Tx, slug string) (domain.Category, error)
+	// FindSlugs maps the current and old slugs that are base or base followed
+	// by "-" and a suffix to the categories using them, deleted or not.
+	FindSlugs(ctx context.Context, tx Tx, base string) (map[string]int, error)
+	FindAll(ctx context.Context, tx Tx) ([]domain.Category, error)
+	// FindChildren returns the children of a category, or the root
+	// categories for parentId 0, in id order.
+	FindChildren(ctx context.Context, tx Tx, parentId int) ([]domain.Category, error)
+	// FindAncestors returns the ancestors of a category from its root down
+	// to its parent, deleted or not.
+	FindAncestors(ctx context.Context, tx Tx, categoryId int) ([]domain.Category, error)
+	// FindDescendants returns the subtree below a category, or every tree
+	// for categoryId 0, level by level so that parents precede children.
+	FindDescendants(ctx context.Context, tx Tx, categoryId int, includeDeleted bool) ([]domain.Category, error)
+	// FindPage returns up to query.Limit categories in ascending id order.
+	FindPage(ctx context.Context, tx Tx, query domain.CategoryQuery) ([]domain.Category, error)
+	// Count counts the categories query selects regardless of its page.
+	Count(ctx context.Context, tx Tx, query domain.CategoryQuery) (int, error)
 }
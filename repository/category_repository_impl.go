@@ -4,80 +4,387 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"golang-restful-api/helper"
+	"fmt"
+	"golang-restful-api/exception"
 	"golang-restful-api/model/domain"
+	"strconv"
+	"strings"
 )
 
+const categoryColumns = "id, parent_id, name, slug, name_key, created_at, updated_at, deleted_at"
+
+// qualifiedCategoryColumns are categoryColumns for queries joining category
+// with other tables.
+var qualifiedCategoryColumns = "category." + strings.ReplaceAll(categoryColumns, ", ", ", category.")
+
 type CategoryRepositoryImplementation struct {
+	Dialect Dialect
 }
 
-func NewCategoryRepository() CategoryRepository {
-	return &CategoryRepositoryImplementation{}
+func NewCategoryRepository(dialect Dialect) CategoryRepository {
+	return &CategoryRepositoryImplementation{Dialect: dialect}
 }
 
-func (repository *CategoryRepositoryImplementation) Save(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category {
-	SQL := "INSERT INTO category(name) VALUES (?)"
+func (repository *CategoryRepositoryImplementation) Save(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error) {
+	SQL := "INSERT INTO category(parent_id, name, slug, name_key, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)"
 
-	result, err := tx.ExecContext(ctx, SQL, category.Name)
-	helper.PanicIfError(err)
-
-	id, err := result.LastInsertId()
-	helper.PanicIfError(err)
+	id, err := repository.Dialect.InsertReturningId(ctx, sqlTx(tx), SQL, nullInt(category.ParentId), category.Name,
+		nullString(category.Slug), nullString(category.NameKey), category.CreatedAt.UTC(), category.UpdatedAt.UTC())
+	if err != nil {
+		return category, repository.translateError(err)
+	}
 
 	category.Id = int(id)
-	return category
+	return category, nil
 }
 
-func (repository *CategoryRepositoryImplementation) Update(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category {
-	SQL := "UPDATE category SET name = ? WHERE id = ?"
+func (repository *CategoryRepositoryImplementation) Update(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error) {
+	// A slug taken back from the old slugs of the category is current again,
+	// and the slug it replaces becomes an old one.
+	if category.Slug != "" {
+		SQL := "DELETE FROM category_old_slug WHERE slug = ? AND category_id = ?"
+		_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), category.Slug, category.Id)
+		if err != nil {
+			return category, translateError(err)
+		}
+	}
+
+	var previous sql.NullString
+	SQL := "SELECT slug FROM category WHERE id = ?"
+	err := sqlTx(tx).QueryRowContext(ctx, repository.Dialect.Rebind(SQL), category.Id).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return category, translateError(err)
+	}
+
+	if previous.Valid && previous.String != category.Slug {
+		SQL = "INSERT INTO category_old_slug(slug, category_id, created_at) VALUES (?, ?, ?)"
+		_, err = sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), previous.String, category.Id, category.UpdatedAt.UTC())
+		if err != nil {
+			return category, repository.translateError(err)
+		}
+	}
 
-	_, err := tx.ExecContext(ctx, SQL, category.Name, category.Id)
-	helper.PanicIfError(err)
+	SQL = "UPDATE category SET parent_id = ?, name = ?, slug = ?, name_key = ?, updated_at = ?, deleted_at = ? WHERE id = ?"
+	_, err = sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), nullInt(category.ParentId), category.Name,
+		nullString(category.Slug), nullString(category.NameKey), category.UpdatedAt.UTC(), nullTime(category.DeletedAt), category.Id)
+	if err != nil {
+		return category, repository.translateError(err)
+	}
 
-	return category
+	return category, nil
 }
 
-func (repository *CategoryRepositoryImplementation) Delete(ctx context.Context, tx *sql.Tx, category domain.Category) {
-	SQL := "DELETE FROM category WHERE id = ?"
+func (repository *CategoryRepositoryImplementation) Delete(ctx context.Context, tx Tx, category domain.Category) error {
+	SQL := "DELETE FROM category_old_slug WHERE category_id = ?"
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), category.Id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	SQL = "DELETE FROM category WHERE id = ?"
+	_, err = sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), category.Id)
 
-	_, err := tx.ExecContext(ctx, SQL, category.Id)
-	helper.PanicIfError(err)
+	return translateError(err)
 }
 
-func (repository *CategoryRepositoryImplementation) FindById(ctx context.Context, tx *sql.Tx, categoryId int) (domain.Category, error) {
-	SQL := "SELECT id, name FROM category WHERE id = ?"
+func (repository *CategoryRepositoryImplementation) FindById(ctx context.Context, tx Tx, categoryId int) (domain.Category, error) {
+	SQL := "SELECT " + categoryColumns + " FROM category WHERE id = ? AND deleted_at IS NULL"
 
-	rows, err := tx.QueryContext(ctx, SQL, categoryId)
-	helper.PanicIfError(err)
-	defer helper.CloseRows(rows)
+	return repository.findOne(ctx, tx, SQL, categoryId)
+}
 
-	category := domain.Category{}
+func (repository *CategoryRepositoryImplementation) FindByIdIncludingDeleted(ctx context.Context, tx Tx, categoryId int) (domain.Category, error) {
+	SQL := "SELECT " + categoryColumns + " FROM category WHERE id = ?"
 
-	if rows.Next() {
-		err := rows.Scan(&category.Id, &category.Name)
-		helper.PanicIfError(err)
-	} else {
-		return category, errors.New("category not found")
+	return repository.findOne(ctx, tx, SQL, categoryId)
+}
+
+func (repository *CategoryRepositoryImplementation) FindByNameKey(ctx context.Context, tx Tx, nameKey string) (domain.Category, error) {
+	SQL := "SELECT " + categoryColumns + " FROM category WHERE name_key = ? AND deleted_at IS NULL"
+
+	return repository.findOne(ctx, tx, SQL, nameKey)
+}
+
+func (repository *CategoryRepositoryImplementation) FindBySlug(ctx context.Context, tx Tx, slug string) (domain.Category, error) {
+	SQL := "SELECT " + categoryColumns + " FROM category WHERE slug = ? AND deleted_at IS NULL"
+
+	return repository.findOne(ctx, tx, SQL, slug)
+}
+
+func (repository *CategoryRepositoryImplementation) FindByOldSlug(ctx context.Context, tx Tx, slug string) (domain.Category, error) {
+	SQL := "SELECT " + qualifiedCategoryColumns + " FROM category" +
+		" JOIN category_old_slug ON category_old_slug.category_id = category.id" +
+		" WHERE category_old_slug.slug = ? AND category.deleted_at IS NULL"
+
+	return repository.findOne(ctx, tx, SQL, slug)
+}
+
+func (repository *CategoryRepositoryImplementation) FindSlugs(ctx context.Context, tx Tx, base string) (map[string]int, error) {
+	pattern := escapeLike(base) + "-%"
+	SQL := "SELECT slug, id FROM category WHERE slug = ? OR slug LIKE ? ESCAPE '!'" +
+		" UNION ALL " +
+		"SELECT slug, category_id FROM category_old_slug WHERE slug = ? OR slug LIKE ? ESCAPE '!'"
+
+	rows, err := sqlTx(tx).QueryContext(ctx, repository.Dialect.Rebind(SQL), base, pattern, base, pattern)
+	if err != nil {
+		return nil, translateError(err)
 	}
+	defer rows.Close()
 
-	return category, nil
+	slugs := map[string]int{}
+	for rows.Next() {
+		var slug string
+		var categoryId int
+		err = rows.Scan(&slug, &categoryId)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		slugs[slug] = categoryId
+	}
+
+	return slugs, translateError(rows.Err())
+}
+
+func (repository *CategoryRepositoryImplementation) FindAll(ctx context.Context, tx Tx) ([]domain.Category, error) {
+	SQL := "SELECT " + categoryColumns + " FROM category WHERE deleted_at IS NULL"
+
+	return repository.findMany(ctx, tx, SQL)
+}
+
+func (repository *CategoryRepositoryImplementation) FindChildren(ctx context.Context, tx Tx, parentId int) ([]domain.Category, error) {
+	if parentId == 0 {
+		SQL := "SELECT " + categoryColumns + " FROM category WHERE parent_id IS NULL AND deleted_at IS NULL ORDER BY id"
+		return repository.findMany(ctx, tx, SQL)
+	}
+
+	SQL := "SELECT " + categoryColumns + " FROM category WHERE parent_id = ? AND deleted_at IS NULL ORDER BY id"
+
+	return repository.findMany(ctx, tx, SQL, parentId)
+}
+
+func (repository *CategoryRepositoryImplementation) FindAncestors(ctx context.Context, tx Tx, categoryId int) ([]domain.Category, error) {
+	SQL := "WITH RECURSIVE ancestor(id, parent_id, distance) AS (" +
+		"SELECT id, parent_id, 0 FROM category WHERE id = ?" +
+		" UNION ALL " +
+		"SELECT category.id, category.parent_id, ancestor.distance + 1 FROM category JOIN ancestor ON category.id = ancestor.parent_id" +
+		") SELECT " + qualifiedCategoryColumns + " FROM category JOIN ancestor ON category.id = ancestor.id" +
+		" WHERE ancestor.distance > 0 ORDER BY ancestor.distance DESC"
+
+	return repository.findMany(ctx, tx, SQL, categoryId)
+}
+
+func (repository *CategoryRepositoryImplementation) FindDescendants(ctx context.Context, tx Tx, categoryId int, includeDeleted bool) ([]domain.Category, error) {
+	var args []interface{}
+	start := []string{"parent_id IS NULL"}
+	if categoryId != 0 {
+		start = []string{"parent_id = ?"}
+		args = append(args, categoryId)
+	}
+	var next []string
+	if !includeDeleted {
+		start = append(start, "deleted_at IS NULL")
+		next = append(next, "category.deleted_at IS NULL")
+	}
+
+	// Without deleted categories the walk stops at them, so the subtrees
+	// below a deleted category are left out too.
+	SQL := "WITH RECURSIVE subtree(id, depth) AS (" +
+		"SELECT id, 1 FROM category" + where(start) +
+		" UNION ALL " +
+		"SELECT category.id, subtree.depth + 1 FROM category JOIN subtree ON category.parent_id = subtree.id" + where(next) +
+		") SELECT " + qualifiedCategoryColumns + " FROM category JOIN subtree ON category.id = subtree.id" +
+		" ORDER BY subtree.depth, category.id"
+
+	return repository.findMany(ctx, tx, SQL, args...)
+}
+
+// categorySortColumns whitelists the columns query.Sort may name, so that
+// no client input is written into the SQL.
+var categorySortColumns = map[string]string{
+	"id":   "id",
+	"name": "name",
 }
 
-func (repository *CategoryRepositoryImplementation) FindAll(ctx context.Context, tx *sql.Tx) []domain.Category {
-	SQL := "SELECT id, name FROM category"
+func (repository *CategoryRepositoryImplementation) FindPage(ctx context.Context, tx Tx, query domain.CategoryQuery) ([]domain.Category, error) {
+	conditions, args := categoryFilter(query)
 
-	rows, err := tx.QueryContext(ctx, SQL)
-	helper.PanicIfError(err)
-	defer helper.CloseRows(rows)
+	// A page before a cursor is read backwards from it, then reversed.
+	backwards := query.Before != nil
+	boundary := query.After
+	if backwards {
+		boundary = query.Before
+	}
+
+	var order []string
+	var sortColumns []domain.SortField
+	var boundaryValues []interface{}
+	for _, sortField := range query.Sort {
+		column, ok := categorySortColumns[sortField.Field]
+		if !ok {
+			return nil, fmt.Errorf("repository: cannot sort categories by %q", sortField.Field)
+		}
+
+		if sortField.Descending != backwards {
+			order = append(order, column+" DESC")
+		} else {
+			order = append(order, column+" ASC")
+		}
+		sortColumns = append(sortColumns, domain.SortField{Field: column, Descending: sortField.Descending})
+		if boundary != nil {
+			boundaryValues = append(boundaryValues, categorySortValue(*boundary, sortField.Field))
+		}
+	}
+
+	if boundary != nil {
+		condition, keysetArgs := keysetCondition(sortColumns, boundaryValues, backwards)
+		conditions = append(conditions, condition)
+		args = append(args, keysetArgs...)
+	}
+
+	SQL := "SELECT " + categoryColumns + " FROM category" + where(conditions)
+	if len(order) > 0 {
+		SQL += " ORDER BY " + strings.Join(order, ", ")
+	}
+	SQL += " LIMIT " + strconv.Itoa(query.Limit)
+	if query.Offset > 0 && boundary == nil {
+		SQL += " OFFSET " + strconv.Itoa(query.Offset)
+	}
+
+	categories, err := repository.findMany(ctx, tx, SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if backwards {
+		for i, j := 0, len(categories)-1; i < j; i, j = i+1, j-1 {
+			categories[i], categories[j] = categories[j], categories[i]
+		}
+	}
+
+	return categories, nil
+}
+
+func (repository *CategoryRepositoryImplementation) Count(ctx context.Context, tx Tx, query domain.CategoryQuery) (int, error) {
+	conditions, args := categoryFilter(query)
+	SQL := "SELECT COUNT(*) FROM category" + where(conditions)
+
+	var count int
+	err := sqlTx(tx).QueryRowContext(ctx, repository.Dialect.Rebind(SQL), args...).Scan(&count)
+
+	return count, translateError(err)
+}
+
+func categoryFilter(query domain.CategoryQuery) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if !query.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if query.Name != "" {
+		conditions = append(conditions, "name = ?")
+		args = append(args, query.Name)
+	}
+	if query.NamePrefix != "" {
+		conditions = append(conditions, "LOWER(name) LIKE LOWER(?) ESCAPE '!'")
+		args = append(args, escapeLike(query.NamePrefix)+"%")
+	}
+	if query.NameContains != "" {
+		conditions = append(conditions, "LOWER(name) LIKE LOWER(?) ESCAPE '!'")
+		args = append(args, "%"+escapeLike(query.NameContains)+"%")
+	}
+	if query.MinId != 0 {
+		conditions = append(conditions, "id >= ?")
+		args = append(args, query.MinId)
+	}
+	if query.MaxId != 0 {
+		conditions = append(conditions, "id <= ?")
+		args = append(args, query.MaxId)
+	}
+
+	return conditions, args
+}
+
+func categorySortValue(category domain.Category, field string) interface{} {
+	if field == "name" {
+		return category.Name
+	}
+
+	return category.Id
+}
+
+func (repository *CategoryRepositoryImplementation) findMany(ctx context.Context, tx Tx, SQL string, args ...interface{}) ([]domain.Category, error) {
+	rows, err := sqlTx(tx).QueryContext(ctx, repository.Dialect.Rebind(SQL), args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
 
 	var categories []domain.Category
 	for rows.Next() {
-		category := domain.Category{}
-		err := rows.Scan(&category.Id, &category.Name)
-		helper.PanicIfError(err)
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
 
 		categories = append(categories, category)
 	}
 
-	return categories
+	return categories, translateError(rows.Err())
+}
+
+func (repository *CategoryRepositoryImplementation) findOne(ctx context.Context, tx Tx, SQL string, args ...interface{}) (domain.Category, error) {
+	rows, err := sqlTx(tx).QueryContext(ctx, repository.Dialect.Rebind(SQL), args...)
+	if err != nil {
+		return domain.Category{}, translateError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return category, translateError(err)
+		}
+		return category, nil
+	} else if rows.Err() != nil {
+		return domain.Category{}, translateError(rows.Err())
+	} else {
+		return domain.Category{}, exception.NewNotFoundError("category not found")
+	}
+}
+
+// translateError additionally reports a violated unique name or slug as a
+// conflict.
+func (repository *CategoryRepositoryImplementation) translateError(err error) error {
+	if repository.Dialect.IsDuplicateKey(err) {
+		return exception.NewConflictError("category name or slug already exists")
+	}
+
+	return translateError(err)
+}
+
+func scanCategory(rows *sql.Rows) (domain.Category, error) {
+	category := domain.Category{}
+	var parentId sql.NullInt64
+	var slug sql.NullString
+	var nameKey sql.NullString
+	var deletedAt sql.NullTime
+
+	err := rows.Scan(&category.Id, &parentId, &category.Name, &slug, &nameKey, &category.CreatedAt, &category.UpdatedAt, &deletedAt)
+	category.ParentId = int(parentId.Int64)
+	category.Slug = slug.String
+	category.NameKey = nameKey.String
+	category.CreatedAt = category.CreatedAt.UTC()
+	category.UpdatedAt = category.UpdatedAt.UTC()
+	category.DeletedAt = deletedAt.Time.UTC()
+
+	return category, err
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullInt(value int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value != 0}
 }
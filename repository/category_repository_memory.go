@@ -0,0 +1,485 @@
+package repository
+
+import (
+	"context"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/domain"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryCategoryRepository keeps categories in memory. Writes are staged per
+// transaction and only become visible to other transactions after commit.
+type MemoryCategoryRepository struct {
+	mutex      sync.RWMutex
+	lastId     int
+	categories map[int]domain.Category
+	// oldSlugs maps old slugs to their categories.
+	oldSlugs map[string]int
+	pending  map[*MemoryTx]*memoryCategoryChanges
+}
+
+type memoryCategoryChanges struct {
+	created map[int]bool
+	saved   map[int]domain.Category
+	deleted map[int]bool
+	// oldSlugs are added, or removed when mapped to 0.
+	oldSlugs map[string]int
+}
+
+func NewMemoryCategoryRepository() CategoryRepository {
+	return &MemoryCategoryRepository{
+		categories: map[int]domain.Category{},
+		oldSlugs:   map[string]int{},
+		pending:    map[*MemoryTx]*memoryCategoryChanges{},
+	}
+}
+
+func (repository *MemoryCategoryRepository) Save(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if repository.duplicate(repository.pending[memoryTx(tx)], category) {
+		return category, duplicateCategoryError()
+	}
+
+	// Like an auto-increment column, ids are never reused even if the
+	// transaction that allocated them rolls back.
+	repository.lastId++
+	category.Id = repository.lastId
+
+	changes := repository.changes(tx)
+	changes.created[category.Id] = true
+	changes.saved[category.Id] = category
+
+	return category, nil
+}
+
+func (repository *MemoryCategoryRepository) Update(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if repository.duplicate(repository.pending[memoryTx(tx)], category) {
+		return category, duplicateCategoryError()
+	}
+
+	changes := repository.changes(tx)
+	previous, ok := repository.find(changes, category.Id)
+	if !ok {
+		return category, nil
+	}
+
+	if category.Slug != "" && repository.visibleOldSlugs(changes)[category.Slug] == category.Id {
+		changes.oldSlugs[category.Slug] = 0
+	}
+	if previous.Slug != "" && previous.Slug != category.Slug {
+		changes.oldSlugs[previous.Slug] = category.Id
+	}
+	changes.saved[category.Id] = category
+
+	return category, nil
+}
+
+func (repository *MemoryCategoryRepository) Delete(ctx context.Context, tx Tx, category domain.Category) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	changes := repository.changes(tx)
+	delete(changes.saved, category.Id)
+	changes.deleted[category.Id] = true
+	for slug, categoryId := range repository.visibleOldSlugs(changes) {
+		if categoryId == category.Id {
+			changes.oldSlugs[slug] = 0
+		}
+	}
+
+	return nil
+}
+
+func (repository *MemoryCategoryRepository) FindById(ctx context.Context, tx Tx, categoryId int) (domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	category, ok := repository.find(repository.pending[memoryTx(tx)], categoryId)
+	if !ok || !category.DeletedAt.IsZero() {
+		return domain.Category{}, exception.NewNotFoundError("category not found")
+	}
+
+	return category, nil
+}
+
+func (repository *MemoryCategoryRepository) FindByIdIncludingDeleted(ctx context.Context, tx Tx, categoryId int) (domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	category, ok := repository.find(repository.pending[memoryTx(tx)], categoryId)
+	if !ok {
+		return category, exception.NewNotFoundError("category not found")
+	}
+
+	return category, nil
+}
+
+func (repository *MemoryCategoryRepository) FindByNameKey(ctx context.Context, tx Tx, nameKey string) (domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	for _, category := range repository.visible(repository.pending[memoryTx(tx)]) {
+		if category.NameKey == nameKey && category.DeletedAt.IsZero() {
+			return category, nil
+		}
+	}
+
+	return domain.Category{}, exception.NewNotFoundError("category not found")
+}
+
+func (repository *MemoryCategoryRepository) FindBySlug(ctx context.Context, tx Tx, slug string) (domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	for _, category := range repository.visible(repository.pending[memoryTx(tx)]) {
+		if category.Slug == slug && category.DeletedAt.IsZero() {
+			return category, nil
+		}
+	}
+
+	return domain.Category{}, exception.NewNotFoundError("category not found")
+}
+
+func (repository *MemoryCategoryRepository) FindByOldSlug(ctx context.Context, tx Tx, slug string) (domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	changes := repository.pending[memoryTx(tx)]
+	categoryId, ok := repository.visibleOldSlugs(changes)[slug]
+	if ok {
+		category, ok := repository.find(changes, categoryId)
+		if ok && category.DeletedAt.IsZero() {
+			return category, nil
+		}
+	}
+
+	return domain.Category{}, exception.NewNotFoundError("category not found")
+}
+
+func (repository *MemoryCategoryRepository) FindSlugs(ctx context.Context, tx Tx, base string) (map[string]int, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	changes := repository.pending[memoryTx(tx)]
+	slugs := map[string]int{}
+	matches := func(slug string) bool {
+		return slug == base || strings.HasPrefix(slug, base+"-")
+	}
+	for _, category := range repository.visible(changes) {
+		if category.Slug != "" && matches(category.Slug) {
+			slugs[category.Slug] = category.Id
+		}
+	}
+	for slug, categoryId := range repository.visibleOldSlugs(changes) {
+		if matches(slug) {
+			slugs[slug] = categoryId
+		}
+	}
+
+	return slugs, nil
+}
+
+func (repository *MemoryCategoryRepository) FindAll(ctx context.Context, tx Tx) ([]domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return repository.filter(repository.pending[memoryTx(tx)], domain.CategoryQuery{}), nil
+}
+
+func (repository *MemoryCategoryRepository) FindChildren(ctx context.Context, tx Tx, parentId int) ([]domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	var children []domain.Category
+	for _, category := range repository.visible(repository.pending[memoryTx(tx)]) {
+		if category.ParentId == parentId && category.DeletedAt.IsZero() {
+			children = append(children, category)
+		}
+	}
+
+	return children, nil
+}
+
+func (repository *MemoryCategoryRepository) FindAncestors(ctx context.Context, tx Tx, categoryId int) ([]domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	changes := repository.pending[memoryTx(tx)]
+	var ancestors []domain.Category
+	category, ok := repository.find(changes, categoryId)
+	for ok && category.ParentId != 0 {
+		category, ok = repository.find(changes, category.ParentId)
+		if ok {
+			ancestors = append([]domain.Category{category}, ancestors...)
+		}
+	}
+
+	return ancestors, nil
+}
+
+func (repository *MemoryCategoryRepository) FindDescendants(ctx context.Context, tx Tx, categoryId int, includeDeleted bool) ([]domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	children := map[int][]domain.Category{}
+	for _, category := range repository.visible(repository.pending[memoryTx(tx)]) {
+		if includeDeleted || category.DeletedAt.IsZero() {
+			children[category.ParentId] = append(children[category.ParentId], category)
+		}
+	}
+
+	var descendants []domain.Category
+	level := children[categoryId]
+	for len(level) > 0 {
+		descendants = append(descendants, level...)
+
+		var next []domain.Category
+		for _, category := range level {
+			next = append(next, children[category.Id]...)
+		}
+		sort.Slice(next, func(i, j int) bool {
+			return next[i].Id < next[j].Id
+		})
+		level = next
+	}
+
+	return descendants, nil
+}
+
+func (repository *MemoryCategoryRepository) FindPage(ctx context.Context, tx Tx, query domain.CategoryQuery) ([]domain.Category, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	categories := repository.filter(repository.pending[memoryTx(tx)], query)
+	sort.SliceStable(categories, func(i, j int) bool {
+		return compareCategories(categories[i], categories[j], query.Sort) < 0
+	})
+
+	start, end := 0, len(categories)
+	switch {
+	case query.After != nil:
+		start = sort.Search(len(categories), func(i int) bool {
+			return compareCategories(categories[i], *query.After, query.Sort) > 0
+		})
+	case query.Before != nil:
+		end = sort.Search(len(categories), func(i int) bool {
+			return compareCategories(categories[i], *query.Before, query.Sort) >= 0
+		})
+		if end-query.Limit > start {
+			start = end - query.Limit
+		}
+	default:
+		start = query.Offset
+		if start > end {
+			start = end
+		}
+	}
+	if start+query.Limit < end {
+		end = start + query.Limit
+	}
+
+	return categories[start:end], nil
+}
+
+func (repository *MemoryCategoryRepository) Count(ctx context.Context, tx Tx, query domain.CategoryQuery) (int, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return len(repository.filter(repository.pending[memoryTx(tx)], query)), nil
+}
+
+// filter applies the filters of query like the SQL repository, where
+// prefixes and substrings ignore case. It must be called with the mutex held.
+func (repository *MemoryCategoryRepository) filter(changes *memoryCategoryChanges, query domain.CategoryQuery) []domain.Category {
+	var categories []domain.Category
+	for _, category := range repository.visible(changes) {
+		name := strings.ToLower(category.Name)
+		switch {
+		case !query.IncludeDeleted && !category.DeletedAt.IsZero():
+		case query.Name != "" && category.Name != query.Name:
+		case query.NamePrefix != "" && !strings.HasPrefix(name, strings.ToLower(query.NamePrefix)):
+		case query.NameContains != "" && !strings.Contains(name, strings.ToLower(query.NameContains)):
+		case query.MinId != 0 && category.Id < query.MinId:
+		case query.MaxId != 0 && category.Id > query.MaxId:
+		default:
+			categories = append(categories, category)
+		}
+	}
+
+	return categories
+}
+
+func compareCategories(a domain.Category, b domain.Category, sortFields []domain.SortField) int {
+	for _, sortField := range sortFields {
+		var result int
+		switch sortField.Field {
+		case "name":
+			result = strings.Compare(a.Name, b.Name)
+		case "id":
+			result = a.Id - b.Id
+		}
+
+		if result != 0 {
+			if sortField.Descending {
+				return -result
+			}
+			return result
+		}
+	}
+
+	return 0
+}
+
+func duplicateCategoryError() error {
+	return exception.NewConflictError("category name or slug already exists")
+}
+
+// duplicate stands in for the unique indexes on name_key and slug and must
+// be called with the mutex held.
+func (repository *MemoryCategoryRepository) duplicate(changes *memoryCategoryChanges, category domain.Category) bool {
+	for _, existing := range repository.visible(changes) {
+		if existing.Id == category.Id {
+			continue
+		}
+		if category.NameKey != "" && existing.NameKey == category.NameKey {
+			return true
+		}
+		if category.Slug != "" && existing.Slug == category.Slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// visibleOldSlugs returns the old slugs a transaction sees. It must be
+// called with the mutex held.
+func (repository *MemoryCategoryRepository) visibleOldSlugs(changes *memoryCategoryChanges) map[string]int {
+	oldSlugs := map[string]int{}
+	for slug, categoryId := range repository.oldSlugs {
+		oldSlugs[slug] = categoryId
+	}
+
+	if changes != nil {
+		for slug, categoryId := range changes.oldSlugs {
+			if categoryId == 0 {
+				delete(oldSlugs, slug)
+			} else {
+				oldSlugs[slug] = categoryId
+			}
+		}
+	}
+
+	return oldSlugs
+}
+
+// visible returns the categories a transaction sees, ordered by id. It must
+// be called with the mutex held.
+func (repository *MemoryCategoryRepository) visible(changes *memoryCategoryChanges) []domain.Category {
+	var categories []domain.Category
+	for id := range repository.categories {
+		if category, ok := repository.find(changes, id); ok {
+			categories = append(categories, category)
+		}
+	}
+
+	if changes != nil {
+		for id, category := range changes.saved {
+			if changes.created[id] {
+				categories = append(categories, category)
+			}
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Id < categories[j].Id
+	})
+
+	return categories
+}
+
+// find must be called with the mutex held; changes may be nil when the
+// transaction has not written anything yet.
+func (repository *MemoryCategoryRepository) find(changes *memoryCategoryChanges, categoryId int) (domain.Category, bool) {
+	if changes != nil {
+		if changes.deleted[categoryId] {
+			return domain.Category{}, false
+		}
+
+		if category, ok := changes.saved[categoryId]; ok {
+			return category, true
+		}
+	}
+
+	category, ok := repository.categories[categoryId]
+
+	return category, ok
+}
+
+// changes must be called with the write lock held.
+func (repository *MemoryCategoryRepository) changes(tx Tx) *memoryCategoryChanges {
+	memoryTx := memoryTx(tx)
+
+	changes, ok := repository.pending[memoryTx]
+	if ok {
+		return changes
+	}
+
+	changes = &memoryCategoryChanges{
+		created:  map[int]bool{},
+		saved:    map[int]domain.Category{},
+		deleted:  map[int]bool{},
+		oldSlugs: map[string]int{},
+	}
+	repository.pending[memoryTx] = changes
+
+	memoryTx.OnFinish(func() error {
+		repository.mutex.RLock()
+		defer repository.mutex.RUnlock()
+
+		// Transactions that committed since the writes were checked may
+		// have taken the same names or slugs.
+		for _, category := range changes.saved {
+			if repository.duplicate(changes, category) {
+				return duplicateCategoryError()
+			}
+		}
+
+		return nil
+	}, func() {
+		repository.mutex.Lock()
+		defer repository.mutex.Unlock()
+
+		for id := range changes.deleted {
+			delete(repository.categories, id)
+		}
+		for id, category := range changes.saved {
+			// Rows removed by a transaction that committed first stay removed.
+			if _, ok := repository.categories[id]; ok || changes.created[id] {
+				repository.categories[id] = category
+			}
+		}
+		for slug, categoryId := range changes.oldSlugs {
+			if categoryId == 0 {
+				delete(repository.oldSlugs, slug)
+			} else {
+				repository.oldSlugs[slug] = categoryId
+			}
+		}
+		delete(repository.pending, memoryTx)
+	}, func() {
+		repository.mutex.Lock()
+		defer repository.mutex.Unlock()
+
+		delete(repository.pending, memoryTx)
+	})
+
+	return changes
+}
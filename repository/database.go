@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+type Database interface {
+	Begin(ctx context.Context) (Tx, error)
+}
+
+type SqlDatabase struct {
+	DB *sql.DB
+}
+
+func NewSqlDatabase(db *sql.DB) Database {
+	return &SqlDatabase{DB: db}
+}
+
+func (database *SqlDatabase) Begin(ctx context.Context) (Tx, error) {
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return tx, nil
+}
+
+func sqlTx(tx Tx) *sql.Tx {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		panic(fmt.Sprintf("repository: expected *sql.Tx, got %T", tx))
+	}
+
+	return sqlTx
+}
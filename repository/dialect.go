@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"strconv"
+	"strings"
+)
+
+// Dialect hides the differences between the supported SQL backends so that
+// repositories can be written once using MySQL-style "?" placeholders.
+type Dialect interface {
+	Name() string
+	DriverName() string
+	Rebind(query string) string
+	InsertReturningId(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error)
+	IsDuplicateKey(err error) bool
+	ForUpdate(query string) string
+}
+
+func NewDialect(name string) (Dialect, error) {
+	switch name {
+	case "mysql":
+		return &MysqlDialect{}, nil
+	case "postgres":
+		return &PostgresDialect{}, nil
+	case "sqlite":
+		return &SqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", name)
+	}
+}
+
+type MysqlDialect struct {
+}
+
+func (dialect *MysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (dialect *MysqlDialect) DriverName() string {
+	return "mysql"
+}
+
+func (dialect *MysqlDialect) Rebind(query string) string {
+	return query
+}
+
+func (dialect *MysqlDialect) InsertReturningId(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	return execLastInsertId(ctx, tx, query, args...)
+}
+
+func (dialect *MysqlDialect) IsDuplicateKey(err error) bool {
+	var mysqlError *mysql.MySQLError
+
+	return errors.As(err, &mysqlError) && mysqlError.Number == 1062
+}
+
+func (dialect *MysqlDialect) ForUpdate(query string) string {
+	return query + " FOR UPDATE"
+}
+
+type PostgresDialect struct {
+}
+
+func (dialect *PostgresDialect) Name() string {
+	return "postgres"
+}
+
+func (dialect *PostgresDialect) DriverName() string {
+	return "postgres"
+}
+
+func (dialect *PostgresDialect) Rebind(query string) string {
+	var builder strings.Builder
+	builder.Grow(len(query) + 8)
+
+	position := 0
+	quoted := false
+	for _, char := range query {
+		switch {
+		case char == '\'':
+			quoted = !quoted
+		case char == '?' && !quoted:
+			position++
+			builder.WriteString("$" + strconv.Itoa(position))
+			continue
+		}
+		builder.WriteRune(char)
+	}
+
+	return builder.String()
+}
+
+func (dialect *PostgresDialect) InsertReturningId(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, dialect.Rebind(query)+" RETURNING id", args...).Scan(&id)
+
+	return id, err
+}
+
+func (dialect *PostgresDialect) IsDuplicateKey(err error) bool {
+	var pqError *pq.Error
+
+	return errors.As(err, &pqError) && pqError.Code == "23505"
+}
+
+func (dialect *PostgresDialect) ForUpdate(query string) string {
+	return query + " FOR UPDATE"
+}
+
+type SqliteDialect struct {
+}
+
+func (dialect *SqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (dialect *SqliteDialect) DriverName() string {
+	return "sqlite3"
+}
+
+func (dialect *SqliteDialect) Rebind(query string) string {
+	return query
+}
+
+func (dialect *SqliteDialect) InsertReturningId(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	return execLastInsertId(ctx, tx, query, args...)
+}
+
+func (dialect *SqliteDialect) IsDuplicateKey(err error) bool {
+	var sqliteError sqlite3.Error
+
+	return errors.As(err, &sqliteError) && sqliteError.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// ForUpdate leaves the query as it is, SQLite has no row locks and lets only
+// one transaction write at a time.
+func (dialect *SqliteDialect) ForUpdate(query string) string {
+	return query
+}
+
+func execLastInsertId(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
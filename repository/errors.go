@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"golang-restful-api/exception"
+	"net"
+)
+
+// translateError marks failures to reach the database as unavailable so
+// callers can tell them apart from bugs in the query itself.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var netError net.Error
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &netError) {
+		return exception.NewUnavailableError(err)
+	}
+
+	return err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// MemoryDatabase commits one transaction at a time, so that the checks of
+// a commit still hold when its changes are applied.
+type MemoryDatabase struct {
+	commitMutex sync.Mutex
+}
+
+func NewMemoryDatabase() Database {
+	return &MemoryDatabase{}
+}
+
+func (database *MemoryDatabase) Begin(ctx context.Context) (Tx, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryTx{database: database}, nil
+}
+
+// MemoryTx lets in-memory repositories stage their changes and apply or
+// discard them when the transaction finishes. A check may refuse the
+// changes, like a unique index would; the whole transaction is then rolled
+// back before any repository applies its part.
+type MemoryTx struct {
+	database  *MemoryDatabase
+	mutex     sync.Mutex
+	done      bool
+	checks    []func() error
+	commits   []func()
+	rollbacks []func()
+}
+
+// OnFinish registers the callbacks of one repository; check may be nil.
+func (tx *MemoryTx) OnFinish(check func() error, commit func(), rollback func()) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if check != nil {
+		tx.checks = append(tx.checks, check)
+	}
+	tx.commits = append(tx.commits, commit)
+	tx.rollbacks = append(tx.rollbacks, rollback)
+}
+
+func (tx *MemoryTx) Commit() error {
+	checks, commits, rollbacks, err := tx.finish()
+	if err != nil {
+		return err
+	}
+
+	tx.database.commitMutex.Lock()
+	defer tx.database.commitMutex.Unlock()
+
+	for _, check := range checks {
+		err = check()
+		if err != nil {
+			for _, rollback := range rollbacks {
+				rollback()
+			}
+			return err
+		}
+	}
+
+	for _, commit := range commits {
+		commit()
+	}
+
+	return nil
+}
+
+func (tx *MemoryTx) Rollback() error {
+	_, _, rollbacks, err := tx.finish()
+	if err != nil {
+		return err
+	}
+
+	for _, rollback := range rollbacks {
+		rollback()
+	}
+
+	return nil
+}
+
+func (tx *MemoryTx) finish() ([]func() error, []func(), []func(), error) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.done {
+		return nil, nil, nil, sql.ErrTxDone
+	}
+	tx.done = true
+
+	return tx.checks, tx.commits, tx.rollbacks, nil
+}
+
+func memoryTx(tx Tx) *MemoryTx {
+	memoryTx, ok := tx.(*MemoryTx)
+	if !ok {
+		panic("repository: in-memory repositories require a transaction from MemoryDatabase")
+	}
+
+	return memoryTx
+}
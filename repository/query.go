@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"golang-restful-api/model/domain"
+	"strings"
+)
+
+// where joins conditions into a WHERE clause, empty without conditions.
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// escapeLike escapes the wildcards of a LIKE pattern with "!", the ESCAPE
+// character of every query using it. A backslash is not used because MySQL
+// treats it as an escape in string literals.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// keysetCondition selects the rows after the row whose sort columns hold
+// values, in the order of sort or, backwards, before it:
+// (a > ?) OR (a = ? AND b > ?) OR ... The fields of sort are column names
+// from a whitelist, the last of them unique.
+func keysetCondition(sort []domain.SortField, values []interface{}, backwards bool) (string, []interface{}) {
+	var alternatives []string
+	var args []interface{}
+	for i, sortField := range sort {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, sort[j].Field+" = ?")
+			args = append(args, values[j])
+		}
+
+		operator := " > ?"
+		if sortField.Descending != backwards {
+			operator = " < ?"
+		}
+		terms = append(terms, sortField.Field+operator)
+		args = append(args, values[i])
+
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"golang-restful-api/model/domain"
+	"time"
+)
+
+type UserRepository interface {
+	Save(ctx context.Context, tx Tx, user domain.User) (domain.User, error)
+	Update(ctx context.Context, tx Tx, user domain.User) (domain.User, error)
+	UpdatePasswordHash(ctx context.Context, tx Tx, user domain.User, passwordHash string) error
+	RecordFailedLogin(ctx context.Context, tx Tx, userId int, maxFailedLogins int, lockedUntil time.Time) error
+	ClearFailedLogins(ctx context.Context, tx Tx, userId int) error
+	FindById(ctx context.Context, tx Tx, userId int) (domain.User, error)
+	FindByIdForUpdate(ctx context.Context, tx Tx, userId int) (domain.User, error)
+	FindByUsername(ctx context.Context, tx Tx, username string) (domain.User, error)
+	FindAll(ctx context.Context, tx Tx) ([]domain.User, error)
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/domain"
+	"strings"
+	"time"
+)
+
+const userColumns = "id, username, password_hash, scopes, failed_logins, locked_until, created_at, password_changed_at"
+
+type UserRepositoryImplementation struct {
+	Dialect Dialect
+}
+
+func NewUserRepository(dialect Dialect) UserRepository {
+	return &UserRepositoryImplementation{Dialect: dialect}
+}
+
+func (repository *UserRepositoryImplementation) Save(ctx context.Context, tx Tx, user domain.User) (domain.User, error) {
+	SQL := "INSERT INTO users(username, password_hash, scopes, failed_logins, locked_until, created_at, password_changed_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
+
+	id, err := repository.Dialect.InsertReturningId(ctx, sqlTx(tx), SQL,
+		user.Username, user.PasswordHash, strings.Join(user.Scopes, " "), user.FailedLogins,
+		nullTime(user.LockedUntil), user.CreatedAt.UTC(), user.PasswordChangedAt.UTC())
+	if err != nil {
+		return user, repository.translateError(err)
+	}
+
+	user.Id = int(id)
+	return user, nil
+}
+
+func (repository *UserRepositoryImplementation) Update(ctx context.Context, tx Tx, user domain.User) (domain.User, error) {
+	SQL := "UPDATE users SET username = ?, password_hash = ?, scopes = ?, failed_logins = ?, locked_until = ?, password_changed_at = ? WHERE id = ?"
+
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL),
+		user.Username, user.PasswordHash, strings.Join(user.Scopes, " "), user.FailedLogins,
+		nullTime(user.LockedUntil), user.PasswordChangedAt.UTC(), user.Id)
+	if err != nil {
+		return user, repository.translateError(err)
+	}
+
+	return user, nil
+}
+
+// UpdatePasswordHash replaces the hash of the password that was checked
+// against user, and nothing if it has been changed since.
+func (repository *UserRepositoryImplementation) UpdatePasswordHash(ctx context.Context, tx Tx, user domain.User, passwordHash string) error {
+	SQL := "UPDATE users SET password_hash = ? WHERE id = ? AND password_hash = ?"
+
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), passwordHash, user.Id, user.PasswordHash)
+	return repository.translateError(err)
+}
+
+// RecordFailedLogin counts a failed login in a single statement, so that
+// concurrent attempts cannot overwrite each other's count. The attempt that
+// reaches maxFailedLogins locks the account until lockedUntil. locked_until
+// is assigned first because MySQL sees earlier assignments in later ones.
+func (repository *UserRepositoryImplementation) RecordFailedLogin(ctx context.Context, tx Tx, userId int, maxFailedLogins int, lockedUntil time.Time) error {
+	SQL := "UPDATE users SET " +
+		"locked_until = CASE WHEN failed_logins + 1 >= ? THEN ? ELSE locked_until END, " +
+		"failed_logins = CASE WHEN failed_logins + 1 >= ? THEN 0 ELSE failed_logins + 1 END " +
+		"WHERE id = ?"
+
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL),
+		maxFailedLogins, nullTime(lockedUntil), maxFailedLogins, userId)
+	return repository.translateError(err)
+}
+
+func (repository *UserRepositoryImplementation) ClearFailedLogins(ctx context.Context, tx Tx, userId int) error {
+	SQL := "UPDATE users SET failed_logins = 0, locked_until = NULL WHERE id = ?"
+
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), userId)
+	return repository.translateError(err)
+}
+
+func (repository *UserRepositoryImplementation) FindById(ctx context.Context, tx Tx, userId int) (domain.User, error) {
+	SQL := "SELECT " + userColumns + " FROM users WHERE id = ?"
+
+	return repository.findOne(ctx, tx, SQL, userId)
+}
+
+func (repository *UserRepositoryImplementation) FindByIdForUpdate(ctx context.Context, tx Tx, userId int) (domain.User, error) {
+	SQL := "SELECT " + userColumns + " FROM users WHERE id = ?"
+
+	return repository.findOne(ctx, tx, repository.Dialect.ForUpdate(SQL), userId)
+}
+
+func (repository *UserRepositoryImplementation) FindByUsername(ctx context.Context, tx Tx, username string) (domain.User, error) {
+	SQL := "SELECT " + userColumns + " FROM users WHERE username = ?"
+
+	return repository.findOne(ctx, tx, SQL, username)
+}
+
+func (repository *UserRepositoryImplementation) FindAll(ctx context.Context, tx Tx) ([]domain.User, error) {
+	SQL := "SELECT " + userColumns + " FROM users ORDER BY id"
+
+	rows, err := sqlTx(tx).QueryContext(ctx, SQL)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		users = append(users, user)
+	}
+
+	return users, translateError(rows.Err())
+}
+
+func (repository *UserRepositoryImplementation) findOne(ctx context.Context, tx Tx, SQL string, args ...interface{}) (domain.User, error) {
+	rows, err := sqlTx(tx).QueryContext(ctx, repository.Dialect.Rebind(SQL), args...)
+	if err != nil {
+		return domain.User{}, translateError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return user, translateError(err)
+		}
+		return user, nil
+	} else if rows.Err() != nil {
+		return domain.User{}, translateError(rows.Err())
+	} else {
+		return domain.User{}, exception.NewNotFoundError("user not found")
+	}
+}
+
+// translateError additionally reports a taken username as a conflict.
+func (repository *UserRepositoryImplementation) translateError(err error) error {
+	if repository.Dialect.IsDuplicateKey(err) {
+		return exception.NewConflictError("username already exists")
+	}
+
+	return translateError(err)
+}
+
+func scanUser(rows *sql.Rows) (domain.User, error) {
+	user := domain.User{}
+	var scopes string
+	var lockedUntil sql.NullTime
+
+	err := rows.Scan(&user.Id, &user.Username, &user.PasswordHash, &scopes, &user.FailedLogins,
+		&lockedUntil, &user.CreatedAt, &user.PasswordChangedAt)
+
+	user.Scopes = strings.Fields(scopes)
+	user.LockedUntil = lockedUntil.Time.UTC()
+	user.CreatedAt = user.CreatedAt.UTC()
+	user.PasswordChangedAt = user.PasswordChangedAt.UTC()
+
+	return user, err
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"golang-restful-api/model/domain"
+)
+
+type UserSessionRepository interface {
+	Save(ctx context.Context, tx Tx, session domain.UserSession) (domain.UserSession, error)
+	Update(ctx context.Context, tx Tx, session domain.UserSession) (domain.UserSession, error)
+	FindByPrefix(ctx context.Context, tx Tx, prefix string) (domain.UserSession, error)
+	// RevokeByUserId revokes every session of the user except exceptId.
+	RevokeByUserId(ctx context.Context, tx Tx, userId int, exceptId int) error
+}
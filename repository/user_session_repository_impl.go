@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/domain"
+)
+
+const userSessionColumns = "id, user_id, prefix, secret_hash, created_at, expires_at, revoked"
+
+type UserSessionRepositoryImplementation struct {
+	Dialect Dialect
+}
+
+func NewUserSessionRepository(dialect Dialect) UserSessionRepository {
+	return &UserSessionRepositoryImplementation{Dialect: dialect}
+}
+
+func (repository *UserSessionRepositoryImplementation) Save(ctx context.Context, tx Tx, session domain.UserSession) (domain.UserSession, error) {
+	SQL := "INSERT INTO user_session(user_id, prefix, secret_hash, created_at, expires_at, revoked) VALUES (?, ?, ?, ?, ?, ?)"
+
+	id, err := repository.Dialect.InsertReturningId(ctx, sqlTx(tx), SQL,
+		session.UserId, session.Prefix, session.SecretHash, session.CreatedAt.UTC(), session.ExpiresAt.UTC(), session.Revoked)
+	if err != nil {
+		return session, translateError(err)
+	}
+
+	session.Id = int(id)
+	return session, nil
+}
+
+func (repository *UserSessionRepositoryImplementation) Update(ctx context.Context, tx Tx, session domain.UserSession) (domain.UserSession, error) {
+	SQL := "UPDATE user_session SET expires_at = ?, revoked = ? WHERE id = ?"
+
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), session.ExpiresAt.UTC(), session.Revoked, session.Id)
+	if err != nil {
+		return session, translateError(err)
+	}
+
+	return session, nil
+}
+
+func (repository *UserSessionRepositoryImplementation) FindByPrefix(ctx context.Context, tx Tx, prefix string) (domain.UserSession, error) {
+	SQL := "SELECT " + userSessionColumns + " FROM user_session WHERE prefix = ?"
+
+	rows, err := sqlTx(tx).QueryContext(ctx, repository.Dialect.Rebind(SQL), prefix)
+	if err != nil {
+		return domain.UserSession{}, translateError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		session, err := scanUserSession(rows)
+		if err != nil {
+			return session, translateError(err)
+		}
+		return session, nil
+	} else if rows.Err() != nil {
+		return domain.UserSession{}, translateError(rows.Err())
+	} else {
+		return domain.UserSession{}, exception.NewNotFoundError("session not found")
+	}
+}
+
+func (repository *UserSessionRepositoryImplementation) RevokeByUserId(ctx context.Context, tx Tx, userId int, exceptId int) error {
+	SQL := "UPDATE user_session SET revoked = ? WHERE user_id = ? AND id <> ? AND revoked = ?"
+
+	_, err := sqlTx(tx).ExecContext(ctx, repository.Dialect.Rebind(SQL), true, userId, exceptId, false)
+	return translateError(err)
+}
+
+func scanUserSession(rows *sql.Rows) (domain.UserSession, error) {
+	session := domain.UserSession{}
+
+	err := rows.Scan(&session.Id, &session.UserId, &session.Prefix, &session.SecretHash,
+		&session.CreatedAt, &session.ExpiresAt, &session.Revoked)
+
+	session.CreatedAt = session.CreatedAt.UTC()
+	session.ExpiresAt = session.ExpiresAt.UTC()
+
+	return session, err
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"golang-restful-api/auth"
+	"golang-restful-api/model/web"
+)
+
+type ApiKeyService interface {
+	Create(ctx context.Context, request web.ApiKeyCreateRequest) (web.ApiKeyIssuedResponse, error)
+	Rotate(ctx context.Context, apiKeyId int) (web.ApiKeyIssuedResponse, error)
+	Revoke(ctx context.Context, apiKeyId int) error
+	FindById(ctx context.Context, apiKeyId int) (web.ApiKeyResponse, error)
+	FindAll(ctx context.Context) ([]web.ApiKeyResponse, error)
+	auth.ApiKeyVerifier
+}
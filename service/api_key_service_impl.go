@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"golang-restful-api/auth"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lastUsedResolution limits how often verifying a key writes last_used_at.
+const lastUsedResolution = time.Minute
+
+type ApiKeyServiceImplementation struct {
+	ApiKeyRepository repository.ApiKeyRepository
+	DB               repository.Database
+	Validate         *validator.Validate
+}
+
+func NewApiKeyService(apiKeyRepository repository.ApiKeyRepository, DB repository.Database, validate *validator.Validate) ApiKeyService {
+	return &ApiKeyServiceImplementation{
+		ApiKeyRepository: apiKeyRepository,
+		DB:               DB,
+		Validate:         validate,
+	}
+}
+
+func (service *ApiKeyServiceImplementation) Create(ctx context.Context, request web.ApiKeyCreateRequest) (response web.ApiKeyIssuedResponse, err error) {
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return response, exception.NewValidationError(err)
+	}
+
+	now := time.Now().UTC()
+	apiKey := domain.ApiKey{
+		Name:      request.Name,
+		Owner:     request.Owner,
+		Scopes:    request.Scopes,
+		CreatedAt: now,
+	}
+	if request.ExpiresAt != nil {
+		if !request.ExpiresAt.After(now) {
+			return response, exception.NewBadRequestError("expires_at must be in the future")
+		}
+		apiKey.ExpiresAt = request.ExpiresAt.UTC()
+	}
+
+	key, err := newApiKeySecret(&apiKey)
+	if err != nil {
+		return response, err
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	apiKey, err = service.ApiKeyRepository.Save(ctx, tx, apiKey)
+	if err != nil {
+		return response, err
+	}
+
+	return web.ApiKeyIssuedResponse{ApiKeyResponse: helper.ToApiKeyResponse(apiKey), Key: key}, nil
+}
+
+// Rotate replaces the secret of a key, keeping its name, owner, scopes and
+// expiry. The old secret stops working immediately.
+func (service *ApiKeyServiceImplementation) Rotate(ctx context.Context, apiKeyId int) (response web.ApiKeyIssuedResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	apiKey, err := service.ApiKeyRepository.FindByIdForUpdate(ctx, tx, apiKeyId)
+	if err != nil {
+		return response, err
+	}
+	if apiKey.Revoked {
+		return response, exception.NewConflictError("api key is revoked")
+	}
+
+	key, err := newApiKeySecret(&apiKey)
+	if err != nil {
+		return response, err
+	}
+	apiKey.LastUsedAt = time.Time{}
+
+	apiKey, err = service.ApiKeyRepository.Update(ctx, tx, apiKey)
+	if err != nil {
+		return response, err
+	}
+
+	return web.ApiKeyIssuedResponse{ApiKeyResponse: helper.ToApiKeyResponse(apiKey), Key: key}, nil
+}
+
+func (service *ApiKeyServiceImplementation) Revoke(ctx context.Context, apiKeyId int) (err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	apiKey, err := service.ApiKeyRepository.FindByIdForUpdate(ctx, tx, apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	apiKey.Revoked = true
+
+	_, err = service.ApiKeyRepository.Update(ctx, tx, apiKey)
+	return err
+}
+
+func (service *ApiKeyServiceImplementation) FindById(ctx context.Context, apiKeyId int) (response web.ApiKeyResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	apiKey, err := service.ApiKeyRepository.FindById(ctx, tx, apiKeyId)
+	if err != nil {
+		return response, err
+	}
+
+	return helper.ToApiKeyResponse(apiKey), nil
+}
+
+func (service *ApiKeyServiceImplementation) FindAll(ctx context.Context) (responses []web.ApiKeyResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	apiKeys, err := service.ApiKeyRepository.FindAll(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ToApiKeyResponses(apiKeys), nil
+}
+
+// VerifyApiKey looks the key up by its public prefix and compares the hash
+// of the secret part in constant time.
+func (service *ApiKeyServiceImplementation) VerifyApiKey(ctx context.Context, key string) (identity auth.Identity, err error) {
+	prefix, secret, ok := strings.Cut(key, ".")
+	if !ok || len(prefix) != 16 || secret == "" {
+		return identity, auth.ErrInvalidCredentials
+	}
+
+	apiKey, err := service.findByPrefix(ctx, prefix)
+	if errors.As(err, &exception.NotFoundError{}) {
+		return identity, auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		return identity, err
+	}
+
+	now := time.Now().UTC()
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(apiKey.SecretHash)) != 1 ||
+		apiKey.Revoked ||
+		(!apiKey.ExpiresAt.IsZero() && !now.Before(apiKey.ExpiresAt)) {
+		return identity, auth.ErrInvalidCredentials
+	}
+
+	if now.Sub(apiKey.LastUsedAt) >= lastUsedResolution {
+		err = service.updateLastUsed(ctx, apiKey, now)
+		if err != nil {
+			return identity, err
+		}
+	}
+
+	return auth.Identity{
+		Type:    "api_key",
+		Subject: strconv.Itoa(apiKey.Id),
+		Name:    apiKey.Name,
+		Scopes:  apiKey.Scopes,
+	}, nil
+}
+
+func (service *ApiKeyServiceImplementation) findByPrefix(ctx context.Context, prefix string) (apiKey domain.ApiKey, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return apiKey, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	return service.ApiKeyRepository.FindByPrefix(ctx, tx, prefix)
+}
+
+func (service *ApiKeyServiceImplementation) updateLastUsed(ctx context.Context, apiKey domain.ApiKey, usedAt time.Time) (err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	return service.ApiKeyRepository.UpdateLastUsed(ctx, tx, apiKey, usedAt)
+}
+
+// newApiKeySecret gives apiKey a new prefix and secret hash and returns the
+// key to hand to the client, "<prefix>.<secret>".
+func newApiKeySecret(apiKey *domain.ApiKey) (string, error) {
+	prefix, secret, err := newPrefixedSecret()
+	if err != nil {
+		return "", err
+	}
+
+	apiKey.Prefix = prefix
+	apiKey.SecretHash = hashSecret(secret)
+
+	return prefix + "." + secret, nil
+}
+
+// newPrefixedSecret returns a 16 hex digit prefix to look a credential up by
+// and a 256-bit secret.
+func newPrefixedSecret() (string, string, error) {
+	random := make([]byte, 8+32)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(random[:8]), base64.RawURLEncoding.EncodeToString(random[8:]), nil
+}
+
+// hashSecret needs no salt or stretching: the secret is 256 random bits, not
+// a password.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
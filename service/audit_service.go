@@ -0,0 +1,10 @@
+package service
+
+import (
+	"context"
+	"golang-restful-api/model/web"
+)
+
+type AuditService interface {
+	FindAll(ctx context.Context, query web.AuditQuery) ([]web.AuditEntryResponse, error)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"golang-restful-api/auth"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"time"
+)
+
+const (
+	AuditCategoryCreate  = "category.create"
+	AuditCategoryUpdate  = "category.update"
+	AuditCategoryDelete  = "category.delete"
+	AuditCategoryRestore = "category.restore"
+	AuditCategoryPurge   = "category.purge"
+	AuditCategoryMove    = "category.move"
+)
+
+// defaultAuditLimit applies when a query does not ask for a limit.
+const defaultAuditLimit = 100
+
+type AuditServiceImplementation struct {
+	AuditRepository repository.AuditRepository
+	DB              repository.Database
+	Validate        *validator.Validate
+}
+
+func NewAuditService(auditRepository repository.AuditRepository, DB repository.Database, validate *validator.Validate) AuditService {
+	return &AuditServiceImplementation{
+		AuditRepository: auditRepository,
+		DB:              DB,
+		Validate:        validate,
+	}
+}
+
+func (service *AuditServiceImplementation) FindAll(ctx context.Context, query web.AuditQuery) (responses []web.AuditEntryResponse, err error) {
+	err = service.Validate.Struct(query)
+	if err != nil {
+		return nil, exception.NewValidationError(err)
+	}
+
+	filter := domain.AuditFilter{
+		Actor:    query.Actor,
+		EntityId: query.EntityId,
+		Action:   query.Action,
+		Limit:    query.Limit,
+	}
+	if query.From != nil {
+		filter.From = *query.From
+	}
+	if query.To != nil {
+		filter.To = *query.To
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	entries, err := service.AuditRepository.FindAll(ctx, tx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ToAuditEntryResponses(entries), nil
+}
+
+// recordAudit saves an entry for a change made in tx, so that the entry and
+// the change are committed or rolled back together. before and after are
+// nil when the entity did not exist.
+func recordAudit(ctx context.Context, tx repository.Tx, auditRepository repository.AuditRepository, action string, entityType string, entityId int, before interface{}, after interface{}) error {
+	identity, _ := auth.IdentityFromContext(ctx)
+
+	entry := domain.AuditEntry{
+		OccurredAt: time.Now().UTC().Truncate(time.Microsecond),
+		Actor:      identity.Type + ":" + identity.Subject,
+		ActorName:  identity.Name,
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   entityId,
+		RequestId:  helper.RequestIdFromContext(ctx),
+		ClientIp:   helper.ClientIpFromContext(ctx),
+	}
+	if identity.Type == "" {
+		entry.Actor = "anonymous"
+	}
+
+	var err error
+	entry.Before, err = auditValue(before)
+	if err != nil {
+		return err
+	}
+	entry.After, err = auditValue(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = auditRepository.Save(ctx, tx, entry)
+	return err
+}
+
+func auditValue(value interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(value)
+
+	return string(encoded), err
+}
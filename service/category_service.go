@@ -6,9 +6,20 @@ import (
 )
 
 type CategoryService interface {
-	Create(ctx context.Context, request web.CategoryCreateRequest) web.CategoryResponse
-	Update(ctx context.Context, request web.CategoryUpdateRequest) web.CategoryResponse
-	Delete(ctx context.Context, categoryId int)
-	FindById(ctx context.Context, categoryId int) web.CategoryResponse
-	FindAll(ctx context.Context) []web.CategoryResponse
+	Create(ctx context.Context, request web.CategoryCreateRequest) (web.CategoryResponse, error)
+	Update(ctx context.Context, request web.CategoryUpdateRequest) (web.CategoryResponse, error)
+	Delete(ctx context.Context, request web.CategoryDeleteRequest) error
+	Restore(ctx context.Context, categoryId int) (web.CategoryResponse, error)
+	Purge(ctx context.Context, categoryId int) error
+	Move(ctx context.Context, request web.CategoryMoveRequest) (web.CategoryResponse, error)
+	FindById(ctx context.Context, categoryId int) (web.CategoryResponse, error)
+	FindBySlug(ctx context.Context, slug string) (web.CategoryResponse, bool, error)
+	FindChildren(ctx context.Context, categoryId int) ([]web.CategoryResponse, error)
+	FindAncestors(ctx context.Context, categoryId int) ([]web.CategoryResponse, error)
+	FindSubtree(ctx context.Context, categoryId int) (web.CategoryTreeResponse, error)
+	FindTree(ctx context.Context) ([]web.CategoryTreeResponse, error)
+	FindAll(ctx context.Context) ([]web.CategoryResponse, error)
+	FindPage(ctx context.Context, request web.CategoryListRequest) (web.CategoryPageResponse, error)
+	RebuildNameKeys(ctx context.Context) (int, error)
+	GenerateMissingSlugs(ctx context.Context) (int, error)
 }
@@ -2,92 +2,778 @@ package service
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"github.com/go-playground/validator/v10"
+	"golang-restful-api/auth"
 	"golang-restful-api/exception"
 	"golang-restful-api/helper"
 	"golang-restful-api/model/domain"
 	"golang-restful-api/model/web"
 	"golang-restful-api/repository"
+	"strconv"
+	"time"
 )
 
 type CategoryServiceImplementation struct {
 	CategoryRepository repository.CategoryRepository
-	DB                 *sql.DB
+	AuditRepository    repository.AuditRepository
+	DB                 repository.Database
 	Validate           *validator.Validate
+	NameRule           NameRule
+	PageSize           PageSize
+	Hierarchy          Hierarchy
 }
 
-func NewCategoryService(categoryRepository repository.CategoryRepository, DB *sql.DB, validate *validator.Validate) CategoryService {
+func NewCategoryService(categoryRepository repository.CategoryRepository, auditRepository repository.AuditRepository, DB repository.Database, validate *validator.Validate, nameRule NameRule, pageSize PageSize, hierarchy Hierarchy) CategoryService {
 	return &CategoryServiceImplementation{
 		CategoryRepository: categoryRepository,
+		AuditRepository:    auditRepository,
 		DB:                 DB,
 		Validate:           validate,
+		NameRule:           nameRule,
+		PageSize:           pageSize,
+		Hierarchy:          hierarchy,
 	}
 }
 
-func (service *CategoryServiceImplementation) Create(ctx context.Context, request web.CategoryCreateRequest) web.CategoryResponse {
-	err := service.Validate.Struct(request)
-	helper.PanicIfError(err)
-
-	tx, err := service.DB.Begin()
-	helper.PanicIfError(err)
-	defer helper.CommitOrRollback(tx)
+func (service *CategoryServiceImplementation) Create(ctx context.Context, request web.CategoryCreateRequest) (response web.CategoryResponse, err error) {
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return response, exception.NewValidationError(err)
+	}
 
+	now := time.Now().UTC().Truncate(time.Microsecond)
 	category := domain.Category{
-		Name: request.Name,
+		ParentId:  request.ParentId,
+		Name:      request.Name,
+		NameKey:   service.NameRule.Key(request.Name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	defer service.explainDuplicate(ctx, &category, &err)
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	err = service.checkParent(ctx, tx, category.ParentId, 0, 1)
+	if err != nil {
+		return response, err
+	}
+
+	err = service.checkUniqueName(ctx, tx, category)
+	if err != nil {
+		return response, err
+	}
+
+	if request.Slug != "" {
+		category.Slug, err = service.chooseSlug(ctx, tx, request.Slug, 0)
+	} else {
+		category.Slug, err = service.generateSlug(ctx, tx, request.Name, 0)
+	}
+	if err != nil {
+		return response, err
 	}
 
-	category = service.CategoryRepository.Save(ctx, tx, category)
+	category, err = service.CategoryRepository.Save(ctx, tx, category)
+	if err != nil {
+		return response, err
+	}
+
+	response = helper.ToCategoryResponse(category)
+	err = recordAudit(ctx, tx, service.AuditRepository, AuditCategoryCreate, "category", category.Id, nil, response)
+	if err != nil {
+		return web.CategoryResponse{}, err
+	}
 
-	return helper.ToCategoryResponse(category)
+	return response, nil
 }
 
-func (service *CategoryServiceImplementation) Update(ctx context.Context, request web.CategoryUpdateRequest) web.CategoryResponse {
-	err := service.Validate.Struct(request)
-	helper.PanicIfError(err)
+func (service *CategoryServiceImplementation) Update(ctx context.Context, request web.CategoryUpdateRequest) (response web.CategoryResponse, err error) {
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return response, exception.NewValidationError(err)
+	}
 
-	tx, err := service.DB.Begin()
-	helper.PanicIfError(err)
-	defer helper.CommitOrRollback(tx)
+	var category domain.Category
+	defer service.explainDuplicate(ctx, &category, &err)
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
 
-	category, err := service.CategoryRepository.FindById(ctx, tx, request.Id)
-	exception.PanicNotFoundError(err)
+	category, err = service.CategoryRepository.FindById(ctx, tx, request.Id)
+	if err != nil {
+		return response, err
+	}
 
+	before := helper.ToCategoryResponse(category)
+	renamed := category.Name != request.Name
 	category.Name = request.Name
+	category.NameKey = service.NameRule.Key(request.Name)
+	category.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	err = service.checkUniqueName(ctx, tx, category)
+	if err != nil {
+		return response, err
+	}
+
+	if request.Slug != "" {
+		category.Slug, err = service.chooseSlug(ctx, tx, request.Slug, category.Id)
+	} else if renamed || category.Slug == "" {
+		category.Slug, err = service.generateSlug(ctx, tx, request.Name, category.Id)
+	}
+	if err != nil {
+		return response, err
+	}
+
+	category, err = service.CategoryRepository.Update(ctx, tx, category)
+	if err != nil {
+		return response, err
+	}
+
+	response = helper.ToCategoryResponse(category)
+	err = recordAudit(ctx, tx, service.AuditRepository, AuditCategoryUpdate, "category", category.Id, before, response)
+	if err != nil {
+		return web.CategoryResponse{}, err
+	}
 
-	category = service.CategoryRepository.Update(ctx, tx, category)
+	return response, nil
+}
+
+// Delete soft-deletes a category. Its name is released for new categories
+// and checked again on restore. Its children are rejected, deleted with it
+// or moved to its parent as the delete policy says.
+func (service *CategoryServiceImplementation) Delete(ctx context.Context, request web.CategoryDeleteRequest) (err error) {
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return exception.NewValidationError(err)
+	}
+
+	policy := DeletePolicy(request.Children)
+	if policy == "" {
+		policy = service.Hierarchy.DeletePolicy
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
 
-	return helper.ToCategoryResponse(category)
+	category, err := service.CategoryRepository.FindById(ctx, tx, request.Id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	switch policy {
+	case DeleteCascade:
+		descendants, err := service.CategoryRepository.FindDescendants(ctx, tx, category.Id, false)
+		if err != nil {
+			return err
+		}
+
+		for _, descendant := range descendants {
+			err = service.softDelete(ctx, tx, descendant, now)
+			if err != nil {
+				return err
+			}
+		}
+	case DeleteReparent:
+		children, err := service.CategoryRepository.FindChildren(ctx, tx, category.Id)
+		if err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			_, err = service.move(ctx, tx, child, category.ParentId, now)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		children, err := service.CategoryRepository.FindChildren(ctx, tx, category.Id)
+		if err != nil {
+			return err
+		}
+
+		if len(children) > 0 {
+			return exception.NewConflictError("category has child categories, move them or delete with children=cascade or children=reparent")
+		}
+	}
+
+	return service.softDelete(ctx, tx, category, now)
 }
 
-func (service *CategoryServiceImplementation) Delete(ctx context.Context, categoryId int) {
-	tx, err := service.DB.Begin()
-	helper.PanicIfError(err)
-	defer helper.CommitOrRollback(tx)
+func (service *CategoryServiceImplementation) softDelete(ctx context.Context, tx repository.Tx, category domain.Category, now time.Time) error {
+	before := helper.ToCategoryResponse(category)
+	category.NameKey = ""
+	category.UpdatedAt = now
+	category.DeletedAt = now
+
+	category, err := service.CategoryRepository.Update(ctx, tx, category)
+	if err != nil {
+		return err
+	}
+
+	return recordAudit(ctx, tx, service.AuditRepository, AuditCategoryDelete, "category", category.Id, before, helper.ToCategoryResponse(category))
+}
+
+func (service *CategoryServiceImplementation) Restore(ctx context.Context, categoryId int) (response web.CategoryResponse, err error) {
+	var category domain.Category
+	defer service.explainDuplicate(ctx, &category, &err)
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	category, err = service.CategoryRepository.FindByIdIncludingDeleted(ctx, tx, categoryId)
+	if err != nil {
+		return response, err
+	}
+	if category.DeletedAt.IsZero() {
+		return response, exception.NewConflictError("category is not deleted")
+	}
+
+	// The category comes back alone; its deleted children stay deleted.
+	if category.ParentId != 0 {
+		_, err = service.CategoryRepository.FindById(ctx, tx, category.ParentId)
+		if errors.As(err, &exception.NotFoundError{}) {
+			return response, exception.NewConflictError("parent category is deleted, restore it first")
+		}
+		if err != nil {
+			return response, err
+		}
+	}
+
+	err = service.checkParent(ctx, tx, category.ParentId, 0, 1)
+	if err != nil {
+		return response, err
+	}
+
+	before := helper.ToCategoryResponse(category)
+	category.NameKey = service.NameRule.Key(category.Name)
+	category.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	category.DeletedAt = time.Time{}
+
+	err = service.checkUniqueName(ctx, tx, category)
+	if err != nil {
+		return response, err
+	}
+
+	category, err = service.CategoryRepository.Update(ctx, tx, category)
+	if err != nil {
+		return response, err
+	}
+
+	response = helper.ToCategoryResponse(category)
+	err = recordAudit(ctx, tx, service.AuditRepository, AuditCategoryRestore, "category", category.Id, before, response)
+	if err != nil {
+		return web.CategoryResponse{}, err
+	}
+
+	return response, nil
+}
+
+// Purge removes a category for good, whether or not it was soft-deleted,
+// together with the deleted categories below it. Children that are not
+// deleted must be moved or deleted first.
+func (service *CategoryServiceImplementation) Purge(ctx context.Context, categoryId int) (err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	category, err := service.CategoryRepository.FindByIdIncludingDeleted(ctx, tx, categoryId)
+	if err != nil {
+		return err
+	}
+
+	descendants, err := service.CategoryRepository.FindDescendants(ctx, tx, category.Id, true)
+	if err != nil {
+		return err
+	}
+	for _, descendant := range descendants {
+		if descendant.DeletedAt.IsZero() {
+			return exception.NewConflictError("category has child categories that are not deleted")
+		}
+	}
+
+	// Children go before their parents, which they reference.
+	for i := len(descendants) - 1; i >= 0; i-- {
+		err = service.purge(ctx, tx, descendants[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return service.purge(ctx, tx, category)
+}
+
+func (service *CategoryServiceImplementation) purge(ctx context.Context, tx repository.Tx, category domain.Category) error {
+	err := service.CategoryRepository.Delete(ctx, tx, category)
+	if err != nil {
+		return err
+	}
+
+	return recordAudit(ctx, tx, service.AuditRepository, AuditCategoryPurge, "category", category.Id, helper.ToCategoryResponse(category), nil)
+}
+
+// Move hangs a category and its subtree below another parent, or makes it
+// a root category. A category cannot move into its own subtree.
+func (service *CategoryServiceImplementation) Move(ctx context.Context, request web.CategoryMoveRequest) (response web.CategoryResponse, err error) {
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return response, exception.NewValidationError(err)
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	category, err := service.CategoryRepository.FindById(ctx, tx, request.Id)
+	if err != nil {
+		return response, err
+	}
+	if category.ParentId == request.ParentId {
+		return helper.ToCategoryResponse(category), nil
+	}
+
+	descendants, err := service.CategoryRepository.FindDescendants(ctx, tx, category.Id, false)
+	if err != nil {
+		return response, err
+	}
+
+	err = service.checkParent(ctx, tx, request.ParentId, category.Id, subtreeHeight(category, descendants))
+	if err != nil {
+		return response, err
+	}
+
+	category, err = service.move(ctx, tx, category, request.ParentId, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		return response, err
+	}
+
+	return helper.ToCategoryResponse(category), nil
+}
+
+func (service *CategoryServiceImplementation) move(ctx context.Context, tx repository.Tx, category domain.Category, parentId int, now time.Time) (domain.Category, error) {
+	before := helper.ToCategoryResponse(category)
+	category.ParentId = parentId
+	category.UpdatedAt = now
+
+	category, err := service.CategoryRepository.Update(ctx, tx, category)
+	if err != nil {
+		return category, err
+	}
+
+	err = recordAudit(ctx, tx, service.AuditRepository, AuditCategoryMove, "category", category.Id, before, helper.ToCategoryResponse(category))
+
+	return category, err
+}
+
+func (service *CategoryServiceImplementation) FindById(ctx context.Context, categoryId int) (response web.CategoryResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
 
 	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
-	exception.PanicNotFoundError(err)
+	if err != nil {
+		return response, err
+	}
 
-	service.CategoryRepository.Delete(ctx, tx, category)
+	return helper.ToCategoryResponse(category), nil
 }
 
-func (service *CategoryServiceImplementation) FindById(ctx context.Context, categoryId int) web.CategoryResponse {
-	tx, err := service.DB.Begin()
-	helper.PanicIfError(err)
-	defer helper.CommitOrRollback(tx)
+func (service *CategoryServiceImplementation) FindAll(ctx context.Context) (responses []web.CategoryResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	categories, err := service.CategoryRepository.FindAll(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ToCategoryResponses(categories), nil
+}
+
+// FindBySlug finds a category by its slug. A slug the category had before
+// finds it too, with moved set so the caller can redirect to the current
+// slug.
+func (service *CategoryServiceImplementation) FindBySlug(ctx context.Context, slug string) (response web.CategoryResponse, moved bool, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, false, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	category, err := service.CategoryRepository.FindBySlug(ctx, tx, slug)
+	if errors.As(err, &exception.NotFoundError{}) {
+		category, err = service.CategoryRepository.FindByOldSlug(ctx, tx, slug)
+		moved = true
+	}
+	if err != nil {
+		return response, false, err
+	}
+
+	return helper.ToCategoryResponse(category), moved, nil
+}
+
+// FindChildren returns the child categories of a category.
+func (service *CategoryServiceImplementation) FindChildren(ctx context.Context, categoryId int) (responses []web.CategoryResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
 
 	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
-	exception.PanicNotFoundError(err)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := service.CategoryRepository.FindChildren(ctx, tx, category.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]web.CategoryResponse{}, helper.ToCategoryResponses(children)...), nil
+}
+
+// FindAncestors returns the breadcrumb of a category, from its root down to
+// its parent.
+func (service *CategoryServiceImplementation) FindAncestors(ctx context.Context, categoryId int) (responses []web.CategoryResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := service.CategoryRepository.FindAncestors(ctx, tx, category.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]web.CategoryResponse{}, helper.ToCategoryResponses(ancestors)...), nil
+}
+
+// FindSubtree returns a category with its subtree nested below it.
+func (service *CategoryServiceImplementation) FindSubtree(ctx context.Context, categoryId int) (response web.CategoryTreeResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
+	if err != nil {
+		return response, err
+	}
+
+	descendants, err := service.CategoryRepository.FindDescendants(ctx, tx, category.Id, false)
+	if err != nil {
+		return response, err
+	}
+
+	return web.CategoryTreeResponse{
+		CategoryResponse: helper.ToCategoryResponse(category),
+		Children:         buildTrees(category.Id, descendants),
+	}, nil
+}
+
+// FindTree returns every root category with its subtree nested below it.
+func (service *CategoryServiceImplementation) FindTree(ctx context.Context) (responses []web.CategoryTreeResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	categories, err := service.CategoryRepository.FindDescendants(ctx, tx, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTrees(0, categories), nil
+}
+
+// FindPage returns one page of the categories matching the filters of
+// request, by offset or, following a cursor of an earlier page, by the
+// position it stopped at. Cursors stay stable while
+// categories are created and deleted; offsets may skip or repeat them.
+func (service *CategoryServiceImplementation) FindPage(ctx context.Context, request web.CategoryListRequest) (response web.CategoryPageResponse, err error) {
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return response, exception.NewValidationError(err)
+	}
+
+	limit, err := service.PageSize.limit(request.Limit)
+	if err != nil {
+		return response, err
+	}
+
+	if request.IncludeDeleted {
+		err = auth.RequireScopes(ctx, auth.ScopeAdmin)
+		if err != nil {
+			return response, err
+		}
+	}
+
+	sortFields, err := parseSort(request.Sort, domain.CategorySortFields)
+	if err != nil {
+		return response, err
+	}
+
+	// One more category than asked for tells whether another page follows.
+	query := domain.CategoryQuery{
+		Name:           request.Name,
+		NamePrefix:     request.NamePrefix,
+		NameContains:   request.NameContains,
+		MinId:          request.MinId,
+		MaxId:          request.MaxId,
+		IncludeDeleted: request.IncludeDeleted,
+		Sort:           sortFields,
+		Offset:         request.Offset,
+		Limit:          limit + 1,
+	}
+	if request.Cursor != "" {
+		if request.Offset != 0 {
+			return response, exception.NewBadRequestError("offset cannot be combined with cursor")
+		}
+
+		cursor, err := decodePageCursor(request.Cursor, sortFields)
+		if err != nil {
+			return response, err
+		}
+		if cursor.Before {
+			query.Before = cursor.category()
+		} else {
+			query.After = cursor.category()
+		}
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	categories, err := service.CategoryRepository.FindPage(ctx, tx, query)
+	if err != nil {
+		return response, err
+	}
+
+	total, err := service.CategoryRepository.Count(ctx, tx, query)
+	if err != nil {
+		return response, err
+	}
+
+	more := len(categories) > limit
+	hasNext, hasPrev := more, query.Offset > 0 || query.After != nil
+	if query.Before != nil {
+		hasNext, hasPrev = true, more
+		if more {
+			categories = categories[1:]
+		}
+	} else if more {
+		categories = categories[:limit]
+	}
 
-	return helper.ToCategoryResponse(category)
+	response.Categories = helper.ToCategoryResponses(categories)
+	response.Page = web.PageResponse{Total: total, Limit: limit}
+	if len(categories) > 0 {
+		if hasNext {
+			response.Page.NextCursor = newPageCursor(sortFields, response.Categories[len(categories)-1], false).encode()
+		}
+		if hasPrev {
+			response.Page.PrevCursor = newPageCursor(sortFields, response.Categories[0], true).encode()
+		}
+	}
+
+	return response, nil
 }
 
-func (service *CategoryServiceImplementation) FindAll(ctx context.Context) []web.CategoryResponse {
-	tx, err := service.DB.Begin()
-	helper.PanicIfError(err)
-	defer helper.CommitOrRollback(tx)
+// RebuildNameKeys recomputes every name_key after the uniqueness rule changed
+// or for categories created before names were checked.
+func (service *CategoryServiceImplementation) RebuildNameKeys(ctx context.Context) (updated int, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
 
-	categories := service.CategoryRepository.FindAll(ctx, tx)
+	categories, err := service.CategoryRepository.FindAll(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
 
-	return helper.ToCategoryResponses(categories)
+	// Stale keys are cleared first so that they cannot collide with the new
+	// keys of other categories.
+	var changed []domain.Category
+	for _, category := range categories {
+		nameKey := service.NameRule.Key(category.Name)
+		if category.NameKey == nameKey {
+			continue
+		}
+
+		category.NameKey = ""
+		_, err = service.CategoryRepository.Update(ctx, tx, category)
+		if err != nil {
+			return 0, err
+		}
+
+		category.NameKey = nameKey
+		changed = append(changed, category)
+	}
+
+	for _, category := range changed {
+		err = service.checkUniqueName(ctx, tx, category)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = service.CategoryRepository.Update(ctx, tx, category)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(changed), nil
+}
+
+// GenerateMissingSlugs gives a slug to every category created before slugs,
+// deleted or not.
+func (service *CategoryServiceImplementation) GenerateMissingSlugs(ctx context.Context) (generated int, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	// Every category descends from a root, so this finds them all.
+	categories, err := service.CategoryRepository.FindDescendants(ctx, tx, 0, true)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, category := range categories {
+		if category.Slug != "" {
+			continue
+		}
+
+		category.Slug, err = service.generateSlug(ctx, tx, category.Name, category.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = service.CategoryRepository.Update(ctx, tx, category)
+		if err != nil {
+			return 0, err
+		}
+		generated++
+	}
+
+	return generated, nil
+}
+
+// checkParent checks that a subtree height levels high can hang below
+// parentId, 0 being the root: the parent exists, is not in the subtree of
+// categoryId, the category being moved if any, and the tree stays within
+// the maximum depth.
+func (service *CategoryServiceImplementation) checkParent(ctx context.Context, tx repository.Tx, parentId int, categoryId int, height int) error {
+	depth := height
+	if parentId != 0 {
+		parent, err := service.CategoryRepository.FindById(ctx, tx, parentId)
+		if errors.As(err, &exception.NotFoundError{}) {
+			return exception.NewBadRequestError("parent category " + strconv.Itoa(parentId) + " does not exist")
+		}
+		if err != nil {
+			return err
+		}
+
+		ancestors, err := service.CategoryRepository.FindAncestors(ctx, tx, parent.Id)
+		if err != nil {
+			return err
+		}
+		for _, ancestor := range append(ancestors, parent) {
+			if ancestor.Id == categoryId {
+				return exception.NewConflictError("a category cannot be moved below itself or its descendants")
+			}
+		}
+
+		depth += len(ancestors) + 1
+	}
+
+	if depth > service.Hierarchy.MaxDepth {
+		return exception.NewBadRequestError("categories cannot be nested more than " + strconv.Itoa(service.Hierarchy.MaxDepth) + " levels deep")
+	}
+
+	return nil
+}
+
+// checkUniqueName finds the category a name collides with, so the conflict
+// can name it. The unique index still catches concurrent writers.
+// explainDuplicate runs once the transaction has finished. When a unique
+// index caught a name that a concurrent transaction took after
+// checkUniqueName passed, it looks that category up so that the conflict
+// reads the same either way.
+func (service *CategoryServiceImplementation) explainDuplicate(ctx context.Context, category *domain.Category, err *error) {
+	var conflict exception.ConflictError
+	if !errors.As(*err, &conflict) || conflict.Conflicting != nil || category.NameKey == "" {
+		return
+	}
+
+	// The failed transaction may be aborted, so the lookup gets its own.
+	tx, errBegin := service.DB.Begin(ctx)
+	if errBegin != nil {
+		return
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if errUnique := service.checkUniqueName(ctx, tx, *category); errors.As(errUnique, &conflict) {
+		*err = errUnique
+	}
+}
+
+func (service *CategoryServiceImplementation) checkUniqueName(ctx context.Context, tx repository.Tx, category domain.Category) error {
+	if category.NameKey == "" {
+		return nil
+	}
+
+	existing, err := service.CategoryRepository.FindByNameKey(ctx, tx, category.NameKey)
+	if errors.As(err, &exception.NotFoundError{}) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.Id == category.Id {
+		return nil
+	}
+
+	return exception.ConflictError{
+		Message:     "a category named " + strconv.Quote(existing.Name) + " already exists",
+		Conflicting: helper.ToCategoryResponse(existing),
+	}
 }
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"golang-restful-api/helper"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+)
+
+// DeletePolicy decides what deleting a category does to its children.
+type DeletePolicy string
+
+const (
+	// DeleteReject refuses to delete a category that has children.
+	DeleteReject DeletePolicy = "reject"
+	// DeleteCascade deletes the whole subtree with the category.
+	DeleteCascade DeletePolicy = "cascade"
+	// DeleteReparent moves the children up to the parent of the category.
+	DeleteReparent DeletePolicy = "reparent"
+)
+
+// Hierarchy bounds the category tree. MaxDepth counts levels, a root
+// category being at depth 1.
+type Hierarchy struct {
+	MaxDepth     int
+	DeletePolicy DeletePolicy
+}
+
+// subtreeHeight counts the levels of the subtree below root, root included,
+// from its descendants in the order of FindDescendants.
+func subtreeHeight(root domain.Category, descendants []domain.Category) int {
+	depths := map[int]int{root.Id: 1}
+	height := 1
+	for _, category := range descendants {
+		depth := depths[category.ParentId] + 1
+		depths[category.Id] = depth
+		if depth > height {
+			height = depth
+		}
+	}
+
+	return height
+}
+
+// buildTrees nests categories, parents before children, below the
+// categories whose parent is parentId.
+func buildTrees(parentId int, categories []domain.Category) []web.CategoryTreeResponse {
+	children := map[int][]domain.Category{}
+	for _, category := range categories {
+		children[category.ParentId] = append(children[category.ParentId], category)
+	}
+
+	var build func(parentId int) []web.CategoryTreeResponse
+	build = func(parentId int) []web.CategoryTreeResponse {
+		trees := []web.CategoryTreeResponse{}
+		for _, category := range children[parentId] {
+			trees = append(trees, web.CategoryTreeResponse{
+				CategoryResponse: helper.ToCategoryResponse(category),
+				Children:         build(category.Id),
+			})
+		}
+		return trees
+	}
+
+	return build(parentId)
+}
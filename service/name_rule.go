@@ -0,0 +1,35 @@
+package service
+
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+// NameRule decides when two category names count as the same name.
+type NameRule string
+
+const (
+	// NameRuleOff allows duplicate names.
+	NameRuleOff NameRule = "off"
+	// NameRuleExact only rejects names that are byte-for-byte equal.
+	NameRuleExact NameRule = "exact"
+	// NameRuleNormalized ignores case, Unicode representation (NFKC) and
+	// leading, trailing and repeated whitespace.
+	NameRuleNormalized NameRule = "normalized"
+)
+
+// Key returns the value stored in the unique name_key column, or "" when
+// the rule allows duplicates.
+func (rule NameRule) Key(name string) string {
+	switch rule {
+	case NameRuleExact:
+		return name
+	case NameRuleNormalized:
+		name = norm.NFKC.String(name)
+		name = strings.Join(strings.Fields(name), " ")
+		return norm.NFKC.String(cases.Fold().String(name))
+	default:
+		return ""
+	}
+}
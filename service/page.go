@@ -0,0 +1,132 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+	"strconv"
+	"strings"
+)
+
+// PageSize bounds the number of items a list request returns.
+type PageSize struct {
+	Default int
+	Max     int
+}
+
+// limit resolves the limit of a list request, 0 meaning the default.
+func (size PageSize) limit(limit int) (int, error) {
+	if limit == 0 {
+		return size.Default, nil
+	}
+	if limit > size.Max {
+		return 0, exception.NewBadRequestError("limit must not exceed " + strconv.Itoa(size.Max))
+	}
+
+	return limit, nil
+}
+
+// parseSort parses a sort parameter such as "name,-id" against the fields
+// that may be sorted by. The unique id is appended unless already present,
+// so that every sort is a total order a cursor can continue.
+func parseSort(value string, fields []string) ([]domain.SortField, error) {
+	var sortFields []domain.SortField
+	seen := map[string]bool{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		sortField := domain.SortField{Field: strings.TrimPrefix(item, "-"), Descending: strings.HasPrefix(item, "-")}
+		if !contains(fields, sortField.Field) {
+			return nil, exception.NewBadRequestError("cannot sort by " + strconv.Quote(sortField.Field) + ", sortable fields are " + strings.Join(fields, ", "))
+		}
+		if seen[sortField.Field] {
+			return nil, exception.NewBadRequestError("cannot sort by " + strconv.Quote(sortField.Field) + " twice")
+		}
+		seen[sortField.Field] = true
+
+		sortFields = append(sortFields, sortField)
+	}
+
+	if !seen["id"] {
+		sortFields = append(sortFields, domain.SortField{Field: "id"})
+	}
+
+	return sortFields, nil
+}
+
+func formatSort(sortFields []domain.SortField) string {
+	items := make([]string, 0, len(sortFields))
+	for _, sortField := range sortFields {
+		if sortField.Descending {
+			items = append(items, "-"+sortField.Field)
+		} else {
+			items = append(items, sortField.Field)
+		}
+	}
+
+	return strings.Join(items, ",")
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pageCursor is the category a page continues after or, with Before, ends
+// before, holding the fields it was sorted by. Clients treat the encoded
+// cursor as opaque, so its fields may change between releases.
+type pageCursor struct {
+	Sort   string `json:"s"`
+	Before bool   `json:"b,omitempty"`
+	Id     int    `json:"i"`
+	Name   string `json:"n,omitempty"`
+}
+
+func newPageCursor(sortFields []domain.SortField, category web.CategoryResponse, before bool) pageCursor {
+	cursor := pageCursor{Sort: formatSort(sortFields), Before: before, Id: category.Id}
+	for _, sortField := range sortFields {
+		if sortField.Field == "name" {
+			cursor.Name = category.Name
+		}
+	}
+
+	return cursor
+}
+
+func (cursor pageCursor) encode() string {
+	content, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+func (cursor pageCursor) category() *domain.Category {
+	return &domain.Category{Id: cursor.Id, Name: cursor.Name}
+}
+
+// decodePageCursor also rejects cursors of a page sorted differently.
+func decodePageCursor(value string, sortFields []domain.SortField) (pageCursor, error) {
+	var cursor pageCursor
+
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(content, &cursor)
+	}
+	if err != nil || cursor.Id <= 0 {
+		return pageCursor{}, exception.NewBadRequestError("cursor is invalid")
+	}
+	if cursor.Sort != formatSort(sortFields) {
+		return pageCursor{}, exception.NewBadRequestError("cursor was issued for sort " + strconv.Quote(cursor.Sort))
+	}
+
+	return cursor, nil
+}
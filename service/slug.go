@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"golang-restful-api/exception"
+	"golang-restful-api/repository"
+	"golang.org/x/text/unicode/norm"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxSlugLength leaves room for a numeric suffix in the slug column.
+const maxSlugLength = 100
+
+// fallbackSlug is used for names without a single letter or digit that can
+// be transliterated.
+const fallbackSlug = "category"
+
+// slugLetters transliterates lowercase characters that do not decompose
+// into an ASCII letter and diacritics, such as "ß", Cyrillic and Greek.
+var slugLetters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th", 'ł': "l", 'ı': "i", '&': "and",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Slugify turns text into a slug: transliterated to lowercase ASCII letters
+// and digits, with every run of other characters collapsed into one "-".
+// It returns "" when nothing of text can be transliterated.
+func Slugify(text string) string {
+	var builder strings.Builder
+	separate := false
+	write := func(letters string) {
+		if separate && builder.Len() > 0 {
+			builder.WriteByte('-')
+		}
+		separate = false
+		builder.WriteString(letters)
+	}
+
+	for _, char := range strings.ToLower(text) {
+		if letters, ok := slugLetters[char]; ok {
+			write(letters)
+			continue
+		}
+
+		// Decomposing separates diacritics from their letters, and
+		// compatibility characters such as "ﬁ" or "²" into plain ones.
+		for _, part := range norm.NFKD.String(string(char)) {
+			part = unicode.ToLower(part)
+			letters, ok := slugLetters[part]
+			switch {
+			case unicode.Is(unicode.Mn, part):
+			case part <= unicode.MaxASCII && (unicode.IsLetter(part) || unicode.IsDigit(part)):
+				write(string(part))
+			case ok:
+				write(letters)
+			default:
+				separate = true
+			}
+		}
+	}
+
+	slug := builder.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+
+	return slug
+}
+
+// generateSlug returns the slug of name, followed by the lowest suffix from
+// -2 up that makes it unique. Slugs the category itself has or had may be
+// taken back.
+func (service *CategoryServiceImplementation) generateSlug(ctx context.Context, tx repository.Tx, name string, categoryId int) (string, error) {
+	base := Slugify(name)
+	if base == "" {
+		base = fallbackSlug
+	}
+
+	taken, err := service.CategoryRepository.FindSlugs(ctx, tx, base)
+	if err != nil {
+		return "", err
+	}
+
+	slug := base
+	for suffix := 2; ; suffix++ {
+		owner, ok := taken[slug]
+		if !ok || owner == categoryId {
+			return slug, nil
+		}
+		slug = base + "-" + strconv.Itoa(suffix)
+	}
+}
+
+// chooseSlug normalizes a slug a client asked for, which must not be in use
+// by another category, now or before.
+func (service *CategoryServiceImplementation) chooseSlug(ctx context.Context, tx repository.Tx, requested string, categoryId int) (string, error) {
+	slug := Slugify(requested)
+	if slug == "" {
+		return "", exception.NewBadRequestError("slug must contain a letter or digit")
+	}
+
+	taken, err := service.CategoryRepository.FindSlugs(ctx, tx, slug)
+	if err != nil {
+		return "", err
+	}
+	if owner, ok := taken[slug]; ok && owner != categoryId {
+		return "", exception.NewConflictError("slug " + strconv.Quote(slug) + " is already in use")
+	}
+
+	return slug, nil
+}
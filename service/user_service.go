@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"golang-restful-api/auth"
+	"golang-restful-api/model/web"
+)
+
+type UserService interface {
+	Create(ctx context.Context, request web.UserCreateRequest) (web.UserResponse, error)
+	// Register creates an account for anyone, with the scopes of the
+	// LoginPolicy, when registration is enabled.
+	Register(ctx context.Context, request web.RegisterRequest) (web.UserResponse, error)
+	Login(ctx context.Context, request web.LoginRequest) (web.LoginResponse, error)
+	// Logout and ChangePassword act on the session of the identity in ctx.
+	Logout(ctx context.Context) error
+	ChangePassword(ctx context.Context, request web.PasswordChangeRequest) error
+	Unlock(ctx context.Context, userId int) error
+	FindById(ctx context.Context, userId int) (web.UserResponse, error)
+	FindAll(ctx context.Context) ([]web.UserResponse, error)
+	auth.SessionVerifier
+}
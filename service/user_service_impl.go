@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"golang-restful-api/auth"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginPolicy configures the sessions and lockout of UserService.
+type LoginPolicy struct {
+	SessionTTL time.Duration
+	// MaxFailedLogins wrong passwords in a row lock an account for
+	// LockoutDuration.
+	MaxFailedLogins int
+	LockoutDuration time.Duration
+	BcryptCost      int
+	// Issuer, when set, hands sessions out as JWTs instead of opaque tokens.
+	Issuer auth.TokenIssuer
+	// Registration lets anyone create an account with RegistrationScopes;
+	// otherwise only admins create accounts.
+	Registration       bool
+	RegistrationScopes []string
+}
+
+const invalidLogin = "invalid username or password"
+
+type UserServiceImplementation struct {
+	UserRepository        repository.UserRepository
+	UserSessionRepository repository.UserSessionRepository
+	DB                    repository.Database
+	Validate              *validator.Validate
+	Policy                LoginPolicy
+
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+}
+
+func NewUserService(userRepository repository.UserRepository, userSessionRepository repository.UserSessionRepository, DB repository.Database, validate *validator.Validate, policy LoginPolicy) UserService {
+	return &UserServiceImplementation{
+		UserRepository:        userRepository,
+		UserSessionRepository: userSessionRepository,
+		DB:                    DB,
+		Validate:              validate,
+		Policy:                policy,
+	}
+}
+
+func (service *UserServiceImplementation) Create(ctx context.Context, request web.UserCreateRequest) (web.UserResponse, error) {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return web.UserResponse{}, exception.NewValidationError(err)
+	}
+
+	return service.create(ctx, request.Username, request.Password, request.Scopes)
+}
+
+func (service *UserServiceImplementation) Register(ctx context.Context, request web.RegisterRequest) (web.UserResponse, error) {
+	if !service.Policy.Registration {
+		return web.UserResponse{}, exception.NewForbiddenError("registration is disabled, ask an admin for an account")
+	}
+
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return web.UserResponse{}, exception.NewValidationError(err)
+	}
+
+	return service.create(ctx, request.Username, request.Password, service.Policy.RegistrationScopes)
+}
+
+func (service *UserServiceImplementation) create(ctx context.Context, username string, password string, scopes []string) (response web.UserResponse, err error) {
+	passwordHash, err := service.hashPassword(password)
+	if err != nil {
+		return response, err
+	}
+
+	now := time.Now().UTC()
+	user := domain.User{
+		Username:          normalizeUsername(username),
+		PasswordHash:      passwordHash,
+		Scopes:            scopes,
+		CreatedAt:         now,
+		PasswordChangedAt: now,
+	}
+	if user.Scopes == nil {
+		user.Scopes = []string{}
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	user, err = service.UserRepository.Save(ctx, tx, user)
+	if err != nil {
+		return response, err
+	}
+
+	return helper.ToUserResponse(user), nil
+}
+
+// Login reports a wrong password and an unknown username alike. The failed
+// attempt it records must be committed, so it is returned apart from err.
+func (service *UserServiceImplementation) Login(ctx context.Context, request web.LoginRequest) (web.LoginResponse, error) {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return web.LoginResponse{}, exception.NewValidationError(err)
+	}
+
+	response, failure, err := service.login(ctx, request)
+	if err != nil {
+		return response, err
+	}
+
+	return response, failure
+}
+
+func (service *UserServiceImplementation) login(ctx context.Context, request web.LoginRequest) (response web.LoginResponse, failure error, err error) {
+	user, err := service.findByUsername(ctx, normalizeUsername(request.Username))
+	if errors.As(err, &exception.NotFoundError{}) {
+		// Spend the time of a real comparison so that response times do not
+		// tell which usernames exist.
+		_ = bcrypt.CompareHashAndPassword(service.dummyPasswordHash(), []byte(request.Password))
+		return response, exception.NewUnauthorizedError(invalidLogin), nil
+	}
+	if err != nil {
+		return response, nil, err
+	}
+
+	// The comparison is slow, it runs before any row is locked. Only the
+	// right password learns that the account is locked.
+	wrongPassword := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password))
+	now := time.Now().UTC()
+	locked := now.Before(user.LockedUntil)
+	if wrongPassword != nil {
+		if locked {
+			return response, exception.NewUnauthorizedError(invalidLogin), nil
+		}
+		return response, exception.NewUnauthorizedError(invalidLogin), service.recordFailedLogin(ctx, user.Id, now)
+	}
+	if locked {
+		return response, lockedLogin(user), nil
+	}
+
+	passwordHash := user.PasswordHash
+	if cost, _ := bcrypt.Cost([]byte(user.PasswordHash)); cost != service.Policy.BcryptCost {
+		passwordHash, err = service.hashPassword(request.Password)
+		if err != nil {
+			return response, nil, err
+		}
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	checked := user
+	user, err = service.UserRepository.FindByIdForUpdate(ctx, tx, checked.Id)
+	if errors.As(err, &exception.NotFoundError{}) {
+		return response, exception.NewUnauthorizedError(invalidLogin), nil
+	}
+	if err != nil {
+		return response, nil, err
+	}
+	if user.PasswordHash != checked.PasswordHash {
+		// The password was changed after it was checked.
+		return response, exception.NewUnauthorizedError(invalidLogin), nil
+	}
+	if now.Before(user.LockedUntil) {
+		return response, lockedLogin(user), nil
+	}
+
+	err = service.UserRepository.ClearFailedLogins(ctx, tx, user.Id)
+	if err != nil {
+		return response, nil, err
+	}
+	user.FailedLogins = 0
+	user.LockedUntil = time.Time{}
+	if passwordHash != user.PasswordHash {
+		err = service.UserRepository.UpdatePasswordHash(ctx, tx, user, passwordHash)
+		if err != nil {
+			return response, nil, err
+		}
+		user.PasswordHash = passwordHash
+	}
+
+	session := domain.UserSession{UserId: user.Id, CreatedAt: now, ExpiresAt: now.Add(service.Policy.SessionTTL)}
+	prefix, secret, err := newPrefixedSecret()
+	if err != nil {
+		return response, nil, err
+	}
+	session.Prefix = prefix
+	token := prefix + "." + secret
+	if service.Policy.Issuer == nil {
+		session.SecretHash = hashSecret(secret)
+	} else {
+		token, err = service.Policy.Issuer.Issue(userIdentity(user, session), session.ExpiresAt)
+		if err != nil {
+			return response, nil, err
+		}
+	}
+
+	session, err = service.UserSessionRepository.Save(ctx, tx, session)
+	if err != nil {
+		return response, nil, err
+	}
+
+	return web.LoginResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: session.ExpiresAt,
+		User:      helper.ToUserResponse(user),
+	}, nil, nil
+}
+
+func (service *UserServiceImplementation) findByUsername(ctx context.Context, username string) (user domain.User, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return user, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	return service.UserRepository.FindByUsername(ctx, tx, username)
+}
+
+func (service *UserServiceImplementation) recordFailedLogin(ctx context.Context, userId int, now time.Time) (err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	return service.UserRepository.RecordFailedLogin(ctx, tx, userId, service.Policy.MaxFailedLogins, now.Add(service.Policy.LockoutDuration))
+}
+
+func lockedLogin(user domain.User) error {
+	return exception.NewUnauthorizedError("account is locked until " + user.LockedUntil.Format(time.RFC3339))
+}
+
+func (service *UserServiceImplementation) Logout(ctx context.Context) (err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	session, err := service.currentSession(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	session.Revoked = true
+
+	_, err = service.UserSessionRepository.Update(ctx, tx, session)
+	return err
+}
+
+// ChangePassword also logs out every other session of the user.
+func (service *UserServiceImplementation) ChangePassword(ctx context.Context, request web.PasswordChangeRequest) (err error) {
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return exception.NewValidationError(err)
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	session, err := service.currentSession(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	user, err := service.UserRepository.FindById(ctx, tx, session.UserId)
+	if err != nil {
+		return err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.CurrentPassword))
+	if err != nil {
+		return exception.NewForbiddenError("current password is incorrect")
+	}
+
+	user.PasswordHash, err = service.hashPassword(request.NewPassword)
+	if err != nil {
+		return err
+	}
+	user.PasswordChangedAt = time.Now().UTC()
+
+	_, err = service.UserRepository.Update(ctx, tx, user)
+	if err != nil {
+		return err
+	}
+
+	return service.UserSessionRepository.RevokeByUserId(ctx, tx, user.Id, session.Id)
+}
+
+func (service *UserServiceImplementation) Unlock(ctx context.Context, userId int) (err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+
+	user.FailedLogins = 0
+	user.LockedUntil = time.Time{}
+
+	_, err = service.UserRepository.Update(ctx, tx, user)
+	return err
+}
+
+func (service *UserServiceImplementation) FindById(ctx context.Context, userId int) (response web.UserResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return response, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		return response, err
+	}
+
+	return helper.ToUserResponse(user), nil
+}
+
+func (service *UserServiceImplementation) FindAll(ctx context.Context) (responses []web.UserResponse, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	users, err := service.UserRepository.FindAll(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ToUserResponses(users), nil
+}
+
+// VerifySession takes the scopes from the user rather than the session, so
+// that changing them applies to sessions already open.
+func (service *UserServiceImplementation) VerifySession(ctx context.Context, token string) (identity auth.Identity, err error) {
+	prefix, secret, ok := strings.Cut(token, ".")
+	if !ok || len(prefix) != 16 || secret == "" {
+		return identity, auth.ErrInvalidCredentials
+	}
+
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return identity, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	session, err := service.activeSession(ctx, tx, prefix)
+	if err != nil {
+		return identity, err
+	}
+	if session.SecretHash == "" || subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(session.SecretHash)) != 1 {
+		return identity, auth.ErrInvalidCredentials
+	}
+
+	user, err := service.UserRepository.FindById(ctx, tx, session.UserId)
+	if err != nil {
+		return identity, err
+	}
+
+	return userIdentity(user, session), nil
+}
+
+func (service *UserServiceImplementation) CheckSession(ctx context.Context, sessionId string) (identity auth.Identity, err error) {
+	tx, err := service.DB.Begin(ctx)
+	if err != nil {
+		return identity, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	session, err := service.activeSession(ctx, tx, sessionId)
+	if err != nil {
+		return identity, err
+	}
+
+	user, err := service.UserRepository.FindById(ctx, tx, session.UserId)
+	if err != nil {
+		return identity, err
+	}
+
+	return userIdentity(user, session), nil
+}
+
+// activeSession reports ErrInvalidCredentials for unknown, logged out and
+// expired sessions.
+func (service *UserServiceImplementation) activeSession(ctx context.Context, tx repository.Tx, prefix string) (domain.UserSession, error) {
+	session, err := service.UserSessionRepository.FindByPrefix(ctx, tx, prefix)
+	if errors.As(err, &exception.NotFoundError{}) {
+		return session, auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		return session, err
+	}
+
+	if session.Revoked || !time.Now().Before(session.ExpiresAt) {
+		return session, auth.ErrInvalidCredentials
+	}
+
+	return session, nil
+}
+
+func (service *UserServiceImplementation) currentSession(ctx context.Context, tx repository.Tx) (domain.UserSession, error) {
+	identity, _ := auth.IdentityFromContext(ctx)
+	if identity.Session == "" {
+		return domain.UserSession{}, exception.NewBadRequestError("request must be authenticated with a session token")
+	}
+
+	return service.UserSessionRepository.FindByPrefix(ctx, tx, identity.Session)
+}
+
+func (service *UserServiceImplementation) hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), service.Policy.BcryptCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", exception.NewBadRequestError("password must not be longer than 72 bytes")
+	}
+
+	return string(hash), err
+}
+
+func (service *UserServiceImplementation) dummyPasswordHash() []byte {
+	service.dummyHashOnce.Do(func() {
+		service.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), service.Policy.BcryptCost)
+	})
+
+	return service.dummyHash
+}
+
+func userIdentity(user domain.User, session domain.UserSession) auth.Identity {
+	return auth.Identity{
+		Type:    "session",
+		Subject: strconv.Itoa(user.Id),
+		Name:    user.Username,
+		Scopes:  user.Scopes,
+		Session: session.Prefix,
+	}
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/auth"
+	"golang-restful-api/cli"
+	"golang-restful-api/helper"
+	"golang-restful-api/middleware"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func truncateApiKey(db *sql.DB) {
+	_, err := db.Exec("DELETE FROM api_key")
+	helper.PanicIfError(err)
+}
+
+func sendWithKey(router http.Handler, key string, method string, target string, body string) (int, map[string]interface{}) {
+	request := httptest.NewRequest(method, "http://localhost:3000"+target, strings.NewReader(body))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", key)
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	var result map[string]interface{}
+	_ = json.NewDecoder(recorder.Result().Body).Decode(&result)
+
+	return recorder.Result().StatusCode, result
+}
+
+func TestApiKeyLifecycle(t *testing.T) {
+	db := setUpDB()
+	defer truncateApiKey(db)
+	router := setUpRouter(db)
+
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/apikeys", `{"name": "storefront", "owner": "web team", "scopes": ["categories:read"]}`)
+	assert.Equal(t, http.StatusOK, code)
+
+	data := created["data"].(map[string]interface{})
+	key := data["key"].(string)
+	id := strconv.Itoa(int(data["id"].(float64)))
+	assert.True(t, strings.HasPrefix(key, data["prefix"].(string)+"."))
+	assert.Equal(t, []interface{}{"categories:read"}, data["scopes"])
+	assert.Nil(t, data["last_used_at"])
+
+	code, _ = sendWithKey(router, key, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithKey(router, key, http.MethodGet, "/api/apikeys", "")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, listed := sendWithKey(router, "RAHASIA", http.MethodGet, "/api/apikeys", "")
+	assert.Equal(t, http.StatusOK, code)
+	listedKey := listed["data"].([]interface{})[0].(map[string]interface{})
+	assert.NotContains(t, listedKey, "key")
+	assert.NotNil(t, listedKey["last_used_at"])
+
+	code, rotated := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/apikeys/"+id+"/rotate", "")
+	assert.Equal(t, http.StatusOK, code)
+	rotatedKey := rotated["data"].(map[string]interface{})["key"].(string)
+	assert.NotEqual(t, key, rotatedKey)
+
+	code, _ = sendWithKey(router, key, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = sendWithKey(router, rotatedKey, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, "/api/apikeys/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = sendWithKey(router, rotatedKey, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/apikeys/"+id+"/rotate", "")
+	assert.Equal(t, http.StatusConflict, code)
+}
+
+func TestApiKeyRejected(t *testing.T) {
+	db := setUpDB()
+	defer truncateApiKey(db)
+	router := setUpRouter(db)
+
+	code, _ := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/apikeys", `{"name": "x", "owner": "y", "scopes": ["everything"]}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/apikeys", `{"name": "x", "owner": "y", "scopes": ["admin"], "expires_at": "2001-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	apiKey, err := setUpApiKeyService(db).Create(context.Background(), web.ApiKeyCreateRequest{Name: "x", Owner: "y", Scopes: []string{auth.ScopeCategoriesRead}})
+	assert.Nil(t, err)
+
+	prefix, _, _ := strings.Cut(apiKey.Key, ".")
+	for _, key := range []string{"garbage", prefix + ".wrong", prefix, "0000000000000000." + strings.Repeat("a", 43)} {
+		code, _ = sendWithKey(router, key, http.MethodGet, "/api/categories", "")
+		assert.Equal(t, http.StatusUnauthorized, code, key)
+	}
+
+	_, err = db.Exec(setUpDialect().Rebind("UPDATE api_key SET expires_at = ? WHERE id = ?"), time.Now().Add(-time.Minute).UTC(), apiKey.Id)
+	assert.Nil(t, err)
+	code, _ = sendWithKey(router, apiKey.Key, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestApiKeyIdentityInContext(t *testing.T) {
+	db := setUpDB()
+	defer truncateApiKey(db)
+	apiKeyService := setUpApiKeyService(db)
+
+	apiKey, err := apiKeyService.Create(context.Background(), web.ApiKeyCreateRequest{Name: "importer", Owner: "ops", Scopes: []string{auth.ScopeCategoriesWrite}})
+	assert.Nil(t, err)
+
+	var identity auth.Identity
+	handler := middleware.NewAuthMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		identity, _ = auth.IdentityFromContext(request.Context())
+	}), []auth.Authenticator{auth.NewApiKeyAuthenticator(apiKeyService)})
+
+	code, _ := sendWithKey(handler, apiKey.Key, http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "api_key", identity.Type)
+	assert.Equal(t, strconv.Itoa(apiKey.Id), identity.Subject)
+	assert.Equal(t, "importer", identity.Name)
+	assert.True(t, identity.HasScope(auth.ScopeCategoriesWrite))
+	assert.False(t, identity.HasScope(auth.ScopeAdmin))
+}
+
+func TestApiKeyLastUsedKeepsRevocation(t *testing.T) {
+	db := setUpDB()
+	defer truncateApiKey(db)
+	ctx := context.Background()
+	apiKeyService := setUpApiKeyService(db)
+	apiKeyRepository := repository.NewApiKeyRepository(setUpDialect())
+	database := repository.NewSqlDatabase(db)
+
+	findById := func(id int) domain.ApiKey {
+		tx, err := database.Begin(ctx)
+		assert.Nil(t, err)
+		defer tx.Rollback()
+		apiKey, err := apiKeyRepository.FindById(ctx, tx, id)
+		assert.Nil(t, err)
+		return apiKey
+	}
+	updateLastUsed := func(apiKey domain.ApiKey) {
+		tx, err := database.Begin(ctx)
+		assert.Nil(t, err)
+		assert.Nil(t, apiKeyRepository.UpdateLastUsed(ctx, tx, apiKey, time.Now()))
+		assert.Nil(t, tx.Commit())
+	}
+
+	// Verifications that read the key before a rotate or revoke must not
+	// write the old row back.
+	rotated, err := apiKeyService.Create(ctx, web.ApiKeyCreateRequest{Name: "rotated", Owner: "ops", Scopes: []string{auth.ScopeCategoriesRead}})
+	assert.Nil(t, err)
+	stale := findById(rotated.Id)
+	_, err = apiKeyService.Rotate(ctx, rotated.Id)
+	assert.Nil(t, err)
+	updateLastUsed(stale)
+	assert.True(t, findById(rotated.Id).LastUsedAt.IsZero())
+	assert.NotEqual(t, stale.SecretHash, findById(rotated.Id).SecretHash)
+
+	revoked, err := apiKeyService.Create(ctx, web.ApiKeyCreateRequest{Name: "revoked", Owner: "ops", Scopes: []string{auth.ScopeCategoriesRead}})
+	assert.Nil(t, err)
+	stale = findById(revoked.Id)
+	assert.Nil(t, apiKeyService.Revoke(ctx, revoked.Id))
+	updateLastUsed(stale)
+	assert.True(t, findById(revoked.Id).Revoked)
+	assert.True(t, findById(revoked.Id).LastUsedAt.IsZero())
+
+	_, err = apiKeyService.VerifyApiKey(ctx, revoked.Key)
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
+
+func TestCliApiKeys(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "cli.db")
+
+	code, _, stderr := runCli(t, dsn, "", "migrate", "up")
+	assert.Equal(t, cli.ExitOK, code, stderr)
+
+	code, stdout, stderr := runCli(t, dsn, "", "apikeys", "create", "-name", "ci", "-owner", "ops", "-scopes", "categories:read, admin", "-expires", "24h")
+	assert.Equal(t, cli.ExitOK, code, stderr)
+	assert.Contains(t, stderr, "shown only once")
+	assert.Regexp(t, `^[0-9a-f]{16}\.[A-Za-z0-9_-]{43}\n$`, stdout)
+
+	code, _, _ = runCli(t, dsn, "", "apikeys", "create", "-name", "ci", "-owner", "ops", "-scopes", "root")
+	assert.Equal(t, cli.ExitError, code)
+
+	code, stdout, _ = runCli(t, dsn, "", "apikeys", "list")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "categories:read,admin")
+	assert.Contains(t, stdout, "active")
+
+	code, stdout, _ = runCli(t, dsn, "", "apikeys", "rotate", "1")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Regexp(t, `^[0-9a-f]{16}\.`, stdout)
+
+	code, _, _ = runCli(t, dsn, "", "apikeys", "revoke", "1")
+	assert.Equal(t, cli.ExitOK, code)
+
+	code, stdout, _ = runCli(t, dsn, "", "apikeys", "list")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "revoked")
+
+	code, _, _ = runCli(t, dsn, "", "apikeys", "revoke")
+	assert.Equal(t, cli.ExitUsage, code)
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/auth"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func truncateAudit(db *sql.DB) {
+	_, err := db.Exec("DELETE FROM audit_log")
+	helper.PanicIfError(err)
+}
+
+func sendWithRequestId(router http.Handler, requestId string, method string, target string, body string) (int, map[string]interface{}) {
+	request := httptest.NewRequest(method, "http://localhost:3000"+target, strings.NewReader(body))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", "RAHASIA")
+	request.Header.Add("X-Request-ID", requestId)
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	var result map[string]interface{}
+	_ = json.NewDecoder(recorder.Result().Body).Decode(&result)
+
+	return recorder.Result().StatusCode, result
+}
+
+func auditEntries(t *testing.T, router http.Handler, query string) []interface{} {
+	code, body := sendWithKey(router, "RAHASIA", http.MethodGet, "/api/audit?"+query, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	entries, _ := body["data"].([]interface{})
+	return entries
+}
+
+func TestAuditCategoryMutations(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	truncateAudit(db)
+	defer truncateAudit(db)
+	router := setUpRouter(db)
+
+	code, created := sendWithRequestId(router, "create-1", http.MethodPost, "/api/categories", `{"name": "Gadget"}`)
+	assert.Equal(t, http.StatusOK, code)
+	id := strconv.Itoa(int(created["data"].(map[string]interface{})["id"].(float64)))
+
+	code, _ = sendWithRequestId(router, "update-1", http.MethodPut, "/api/categories/"+id, `{"name": "Gadgets"}`)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithRequestId(router, "delete-1", http.MethodDelete, "/api/categories/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	entries := auditEntries(t, router, "entity_id="+id)
+	assert.Equal(t, 3, len(entries))
+
+	deleted := entries[0].(map[string]interface{})
+	assert.Equal(t, "category.delete", deleted["action"])
+	assert.Equal(t, "delete-1", deleted["request_id"])
+	before := deleted["before"].(map[string]interface{})
+	assert.Equal(t, created["data"].(map[string]interface{})["id"], before["id"])
+	assert.Equal(t, "Gadgets", before["name"])
+	assert.Nil(t, before["deleted_at"])
+	assert.NotNil(t, deleted["after"].(map[string]interface{})["deleted_at"])
+
+	updated := entries[1].(map[string]interface{})
+	assert.Equal(t, "category.update", updated["action"])
+	assert.Equal(t, "Gadget", updated["before"].(map[string]interface{})["name"])
+	assert.Equal(t, "Gadgets", updated["after"].(map[string]interface{})["name"])
+
+	createdEntry := entries[2].(map[string]interface{})
+	assert.Equal(t, "category.create", createdEntry["action"])
+	assert.Equal(t, "static_key:static", createdEntry["actor"])
+	assert.Equal(t, "auth.api_key", createdEntry["actor_name"])
+	assert.Equal(t, "category", createdEntry["entity_type"])
+	assert.Equal(t, "192.0.2.1", createdEntry["client_ip"])
+	assert.Nil(t, createdEntry["before"])
+}
+
+func TestAuditFilters(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	truncateAudit(db)
+	defer truncateAudit(db)
+	router := setUpRouter(db)
+
+	start := time.Now().Add(-time.Second).UTC()
+	for _, name := range []string{"Books", "Music"} {
+		code, _ := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "`+name+`"}`)
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	assert.Equal(t, 2, len(auditEntries(t, router, "action=category.create&actor=static_key:static")))
+	assert.Equal(t, 0, len(auditEntries(t, router, "action=category.delete")))
+	assert.Equal(t, 0, len(auditEntries(t, router, "actor=api_key:1")))
+	assert.Equal(t, 1, len(auditEntries(t, router, "limit=1")))
+	assert.Equal(t, 2, len(auditEntries(t, router, "from="+url.QueryEscape(start.Format(time.RFC3339)))))
+	assert.Equal(t, 0, len(auditEntries(t, router, "to="+url.QueryEscape(start.Format(time.RFC3339)))))
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodGet, "/api/audit?entity_id=abc", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "entity_id must be an integer", body["data"])
+
+	code, body = sendWithKey(router, "RAHASIA", http.MethodGet, "/api/audit?from=yesterday", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "from must be an RFC 3339 time", body["data"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodGet, "/api/audit?action=category.read", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+type failingAuditRepository struct {
+	repository.AuditRepository
+}
+
+func (repository failingAuditRepository) Save(ctx context.Context, tx repository.Tx, entry domain.AuditEntry) (domain.AuditEntry, error) {
+	return entry, errors.New("audit log unavailable")
+}
+
+func TestAuditSharesTransaction(t *testing.T) {
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{Type: "api_key", Subject: "7", Name: "importer"})
+	categoryRepository := repository.NewMemoryCategoryRepository()
+	database := repository.NewMemoryDatabase()
+
+	failing := service.NewCategoryService(categoryRepository, failingAuditRepository{repository.NewMemoryAuditRepository()}, database, validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	_, err := failing.Create(ctx, web.CategoryCreateRequest{Name: "Books"})
+	assert.NotNil(t, err)
+
+	auditRepository := repository.NewMemoryAuditRepository()
+	categoryService := service.NewCategoryService(categoryRepository, auditRepository, database, validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	categories, err := categoryService.FindAll(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, categories)
+
+	_, err = categoryService.Create(ctx, web.CategoryCreateRequest{Name: "Books"})
+	assert.Nil(t, err)
+	_, err = categoryService.Create(ctx, web.CategoryCreateRequest{Name: "books"})
+	assert.NotNil(t, err)
+
+	auditService := service.NewAuditService(auditRepository, database, validator.New())
+	entries, err := auditService.FindAll(ctx, web.AuditQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "api_key:7", entries[0].Actor)
+	assert.Equal(t, "importer", entries[0].ActorName)
+}
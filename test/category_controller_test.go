@@ -4,62 +4,135 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"github.com/go-playground/validator/v10"
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"golang-restful-api/app"
+	"golang-restful-api/auth"
+	"golang-restful-api/config"
 	"golang-restful-api/controller"
+	"golang-restful-api/exception"
+	"golang-restful-api/health"
 	"golang-restful-api/helper"
+	"golang-restful-api/i18n"
 	"golang-restful-api/middleware"
+	"golang-restful-api/migration"
 	"golang-restful-api/model/domain"
 	"golang-restful-api/repository"
 	"golang-restful-api/service"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 )
 
+// The tests run against SQLite unless TEST_DB_DRIVER and TEST_DB_DSN point
+// them at another backend, e.g. TEST_DB_DRIVER=mysql
+// TEST_DB_DSN="devtest:root@tcp(localhost:3306)/devtest".
+func testDatabaseConfig() config.DatabaseConfig {
+	databaseConfig := config.Default().Database
+	databaseConfig.Driver = "sqlite"
+	databaseConfig.DSN = "file:" + filepath.Join(os.TempDir(), "golang-restful-api-test.db") + "?_busy_timeout=5000"
+
+	if driver := os.Getenv("TEST_DB_DRIVER"); driver != "" {
+		databaseConfig.Driver = driver
+		databaseConfig.DSN = os.Getenv("TEST_DB_DSN")
+	}
+
+	return databaseConfig
+}
+
+func setUpDialect() repository.Dialect {
+	return app.NewDialect(testDatabaseConfig())
+}
+
 func setUpDB() *sql.DB {
-	db, err := sql.Open("mysql", "devtest:root@tcp(localhost:3306)/devtest")
+	dialect := setUpDialect()
+	db := app.NewDB(testDatabaseConfig(), dialect)
+
+	migrator, err := migration.NewMigrator(db, dialect)
 	helper.PanicIfError(err)
 
-	db.SetMaxIdleConns(5)
-	db.SetMaxIdleConns(50)
-	db.SetConnMaxIdleTime(10 * time.Minute)
-	db.SetConnMaxLifetime(60 * time.Minute)
+	err = migrator.Up(context.Background())
+	helper.PanicIfError(err)
 
 	return db
 }
 
+// testPageSize keeps pages small enough to page through in a test.
+var testPageSize = service.PageSize{Default: 3, Max: 5}
+
+// testHierarchy allows trees just deep enough to test the limit.
+var testHierarchy = service.Hierarchy{MaxDepth: 3, DeletePolicy: service.DeleteReject}
+
 func setUpRouter(db *sql.DB) http.Handler {
-	validate := validator.New()
+	return setUpRouterWithErrorFormat(db, exception.FormatEnvelope)
+}
+
+func setUpRouterWithErrorFormat(db *sql.DB, format exception.Format) http.Handler {
+	validate := app.NewValidator()
+
+	categoryRepository := repository.NewCategoryRepository(setUpDialect())
+	categoryService := service.NewCategoryService(categoryRepository, repository.NewAuditRepository(setUpDialect()), repository.NewSqlDatabase(db), validate, service.NameRuleNormalized, testPageSize, testHierarchy)
+	decoder := controller.NewRequestDecoder(1<<20, false)
+	categoryController := controller.NewCategoryController(categoryService, decoder)
 
-	categoryRepository := repository.NewCategoryRepository()
-	categoryService := service.NewCategoryService(categoryRepository, db, validate)
-	categoryController := controller.NewCategoryController(categoryService)
+	apiKeyService := setUpApiKeyService(db)
+	apiKeyController := controller.NewApiKeyController(apiKeyService, decoder)
 
-	router := app.NewRouter(categoryController)
+	userService := setUpUserService(db, nil)
+	userController := controller.NewUserController(userService, decoder)
 
-	return middleware.NewAuthMiddleware(router)
+	auditController := controller.NewAuditController(service.NewAuditService(repository.NewAuditRepository(setUpDialect()), repository.NewSqlDatabase(db), validate))
+
+	checks := health.NewHealth(time.Second)
+	checks.Register(health.NewDatabaseChecker(db))
+	healthController := controller.NewHealthController(checks)
+
+	router := app.NewRouter(categoryController, apiKeyController, userController, auditController, healthController)
+
+	translator, err := i18n.NewTranslator(validate)
+	helper.PanicIfError(err)
+
+	var handler http.Handler = middleware.NewLocaleMiddleware(router, translator)
+	authenticators := []auth.Authenticator{auth.NewStaticKeyAuthenticator("RAHASIA"), auth.NewApiKeyAuthenticator(apiKeyService), auth.NewSessionAuthenticator(userService)}
+	handler = middleware.NewAuthMiddleware(handler, authenticators, app.PublicPaths...)
+
+	handler = middleware.NewErrorFormatMiddleware(handler, format)
+	handler = middleware.NewClientIpMiddleware(handler, "")
+
+	return middleware.NewRequestIdMiddleware(handler)
+}
+
+func setUpApiKeyService(db *sql.DB) service.ApiKeyService {
+	return service.NewApiKeyService(repository.NewApiKeyRepository(setUpDialect()), repository.NewSqlDatabase(db), app.NewValidator())
 }
 
+// truncateCategory deletes rather than truncates, which the foreign keys
+// referencing category do not allow. Parents are unlinked first because
+// MySQL checks the reference row by row.
 func truncateCategory(db *sql.DB) {
-	_, err := db.Exec("TRUNCATE category")
-	helper.PanicIfError(err)
+	for _, SQL := range []string{"DELETE FROM category_old_slug", "UPDATE category SET parent_id = NULL", "DELETE FROM category"} {
+		_, err := db.Exec(SQL)
+		helper.PanicIfError(err)
+	}
 }
 
 func generateData(db *sql.DB) domain.Category {
 	tx, errBegin := db.Begin()
 	helper.PanicIfError(errBegin)
 
-	categoryRepository := repository.NewCategoryRepository()
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	categoryRepository := repository.NewCategoryRepository(setUpDialect())
+	category, errSave := categoryRepository.Save(context.Background(), tx, domain.Category{
 		Name: "name_test",
 	})
+	helper.PanicIfError(errSave)
 	errCommit := tx.Commit()
 	helper.PanicIfError(errCommit)
 
@@ -121,6 +194,40 @@ func TestCreateCategoryFailed(t *testing.T) {
 	assert.Equal(t, http.StatusText(http.StatusBadRequest), responseBody["status"])
 }
 
+func TestCreateCategoryFailedTranslated(t *testing.T) {
+	db := setUpDB()
+	defer truncateCategory(db)
+	router := setUpRouter(db)
+
+	requestBody := strings.NewReader(`{"name" : ""}`)
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", requestBody)
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept-Language", "fr-CH;q=0.5, id-ID, en;q=0.8")
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "id", response.Header.Get("Content-Language"))
+
+	body, errReadAll := io.ReadAll(response.Body)
+	helper.PanicIfError(errReadAll)
+
+	var responseBody map[string]interface{}
+	errUnmarshal := json.Unmarshal(body, &responseBody)
+	helper.PanicIfError(errUnmarshal)
+
+	fieldErrors := responseBody["data"].([]interface{})
+	assert.Len(t, fieldErrors, 1)
+
+	fieldError := fieldErrors[0].(map[string]interface{})
+	assert.Equal(t, "name", fieldError["field"])
+	assert.Equal(t, "required", fieldError["rule"])
+	assert.Equal(t, "name wajib diisi", fieldError["message"])
+}
+
 func TestUpdateCategorySuccess(t *testing.T) {
 	db := setUpDB()
 	defer truncateCategory(db)
@@ -341,3 +448,7 @@ func TestUnauthorized(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, int(responseBody["code"].(float64)))
 	assert.Equal(t, http.StatusText(http.StatusUnauthorized), responseBody["status"])
 }
+
+func staticKey(key string) []auth.Authenticator {
+	return []auth.Authenticator{auth.NewStaticKeyAuthenticator(key)}
+}
@@ -0,0 +1,158 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/controller"
+	"golang-restful-api/health"
+	"golang-restful-api/helper"
+	"golang-restful-api/middleware"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryRepositoryCommitAndRollback(t *testing.T) {
+	ctx := context.Background()
+	database := repository.NewMemoryDatabase()
+	categoryRepository := repository.NewMemoryCategoryRepository()
+
+	tx, err := database.Begin(ctx)
+	assert.Nil(t, err)
+	category, err := categoryRepository.Save(ctx, tx, domain.Category{Name: "committed"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, category.Id)
+
+	other, err := database.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = categoryRepository.FindById(ctx, other, category.Id)
+	assert.NotNil(t, err, "uncommitted rows must not be visible to other transactions")
+
+	assert.Nil(t, tx.Commit())
+	found, err := categoryRepository.FindById(ctx, other, category.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "committed", found.Name)
+	assert.Nil(t, other.Commit())
+
+	tx, err = database.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = categoryRepository.Update(ctx, tx, domain.Category{Id: category.Id, Name: "renamed"})
+	assert.Nil(t, err)
+	_, err = categoryRepository.Save(ctx, tx, domain.Category{Name: "discarded"})
+	assert.Nil(t, err)
+	categories, err := categoryRepository.FindAll(ctx, tx)
+	assert.Nil(t, err)
+	assert.Len(t, categories, 2)
+	assert.Nil(t, tx.Rollback())
+	assert.NotNil(t, tx.Commit())
+
+	tx, err = database.Begin(ctx)
+	assert.Nil(t, err)
+	categories, err = categoryRepository.FindAll(ctx, tx)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.Category{{Id: 1, Name: "committed"}}, categories)
+	category, err = categoryRepository.Save(ctx, tx, domain.Category{Name: "next"})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, category.Id)
+	assert.Nil(t, tx.Commit())
+}
+
+func TestMemoryRepositoryRejectsDuplicatesAtCommit(t *testing.T) {
+	ctx := context.Background()
+	database := repository.NewMemoryDatabase()
+	categoryRepository := repository.NewMemoryCategoryRepository()
+	auditRepository := repository.NewMemoryAuditRepository()
+
+	first, err := database.Begin(ctx)
+	assert.Nil(t, err)
+	second, err := database.Begin(ctx)
+	assert.Nil(t, err)
+
+	// Neither transaction sees the row the other one staged.
+	_, err = categoryRepository.Save(ctx, first, domain.Category{Name: "Books", NameKey: "books"})
+	assert.Nil(t, err)
+	// The audit entry comes first, so its repository would be applied
+	// before the category check fails if checks did not run first.
+	_, err = auditRepository.Save(ctx, second, domain.AuditEntry{Action: "category.create"})
+	assert.Nil(t, err)
+	_, err = categoryRepository.Save(ctx, second, domain.Category{Name: "BOOKS", NameKey: "books"})
+	assert.Nil(t, err)
+
+	assert.Nil(t, first.Commit())
+	err = second.Commit()
+	assert.Equal(t, "category name or slug already exists", err.Error())
+
+	tx, err := database.Begin(ctx)
+	assert.Nil(t, err)
+	categories, err := categoryRepository.FindAll(ctx, tx)
+	assert.Nil(t, err)
+	assert.Len(t, categories, 1)
+	assert.Equal(t, "Books", categories[0].Name)
+	entries, err := auditRepository.FindAll(ctx, tx, domain.AuditFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+	assert.Nil(t, tx.Commit())
+}
+
+func TestMemoryRepositoryConcurrentCreate(t *testing.T) {
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleOff, testPageSize, testHierarchy)
+
+	var wait sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			_, err := categoryService.Create(context.Background(), web.CategoryCreateRequest{Name: "concurrent"})
+			assert.Nil(t, err)
+		}()
+	}
+	wait.Wait()
+
+	categories, err := categoryService.FindAll(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, categories, 50)
+	assert.Equal(t, 50, categories[49].Id)
+}
+
+func TestMemoryRepositoryThroughController(t *testing.T) {
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	healthController := controller.NewHealthController(health.NewHealth(time.Second))
+	router := middleware.NewAuthMiddleware(app.NewRouter(controller.NewCategoryController(categoryService, controller.NewRequestDecoder(1<<20, false)), controller.NewApiKeyController(nil, nil), controller.NewUserController(nil, nil), controller.NewAuditController(nil), healthController), staticKey("RAHASIA"))
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name" : "name_test"}`))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/1", nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder = httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	body, errReadAll := io.ReadAll(response.Body)
+	helper.PanicIfError(errReadAll)
+
+	var responseBody map[string]interface{}
+	errUnmarshal := json.Unmarshal(body, &responseBody)
+	helper.PanicIfError(errUnmarshal)
+
+	assert.Equal(t, "name_test", responseBody["data"].(map[string]interface{})["name"])
+}
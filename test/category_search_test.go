@@ -0,0 +1,143 @@
+package test
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+var searchNames = []string{"Books", "Board Games", "audio books", "Music", "50% Off", "500 Deals", "Bookends"}
+
+func setUpSearch(t *testing.T) (http.Handler, []int) {
+	db := setUpDB()
+	truncateCategory(db)
+	t.Cleanup(func() { truncateCategory(db) })
+	router := setUpRouter(db)
+
+	var ids []int
+	for _, name := range searchNames {
+		code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "`+name+`"}`)
+		assert.Equal(t, http.StatusOK, code)
+		ids = append(ids, int(created["data"].(map[string]interface{})["id"].(float64)))
+	}
+
+	return router, ids
+}
+
+func TestCategoryFilters(t *testing.T) {
+	router, ids := setUpSearch(t)
+
+	tests := []struct {
+		query string
+		names []string
+	}{
+		{"name=Books", []string{"Books"}},
+		{"name=books", nil},
+		{"name_prefix=boo&limit=5", []string{"Books", "Bookends"}},
+		{"name_contains=BOOK&limit=5", []string{"Books", "audio books", "Bookends"}},
+		{"name_contains=" + url.QueryEscape("0%"), []string{"50% Off"}},
+		{"name_prefix=" + url.QueryEscape("5_"), nil},
+		{"min_id=" + strconv.Itoa(ids[2]) + "&max_id=" + strconv.Itoa(ids[3]), []string{"audio books", "Music"}},
+		{"name_prefix=b&max_id=" + strconv.Itoa(ids[1]), []string{"Books", "Board Games"}},
+	}
+
+	for _, test := range tests {
+		page := getCategoryPage(t, router, test.query)
+		assert.Equal(t, http.StatusOK, page.Code, test.query)
+		assert.Equal(t, test.names, pageNames(page), test.query)
+		assert.Equal(t, len(test.names), page.Page.Total, test.query)
+	}
+}
+
+func TestCategorySort(t *testing.T) {
+	router, _ := setUpSearch(t)
+
+	// Names differing only in case are left out, since their order depends
+	// on the collation of the database.
+	page := getCategoryPage(t, router, "sort=name&name_prefix=b&limit=5")
+	assert.Equal(t, []string{"Board Games", "Bookends", "Books"}, pageNames(page))
+
+	page = getCategoryPage(t, router, "sort=-name&name_prefix=b&limit=5")
+	assert.Equal(t, []string{"Books", "Bookends", "Board Games"}, pageNames(page))
+
+	page = getCategoryPage(t, router, "sort=-id&limit=2")
+	assert.Equal(t, []string{"Bookends", "500 Deals"}, pageNames(page))
+}
+
+func TestCategorySortedCursor(t *testing.T) {
+	router, _ := setUpSearch(t)
+
+	var names []string
+	query := "sort=-name,id&limit=2"
+	for pages := 0; pages < 10; pages++ {
+		page := getCategoryPage(t, router, query)
+		names = append(names, pageNames(page)...)
+		if page.Page.NextCursor == "" {
+			break
+		}
+		query = "sort=-name,id&limit=2&cursor=" + page.Page.NextCursor
+	}
+	all := pageNames(getCategoryPage(t, router, "sort=-name,id&limit=5"))
+	assert.Equal(t, all, names[:5])
+	assert.Equal(t, len(searchNames), len(names))
+
+	page := getCategoryPage(t, router, "sort=-name,id&limit=2")
+	second := getCategoryPage(t, router, "sort=-name,id&limit=2&cursor="+page.Page.NextCursor)
+	back := getCategoryPage(t, router, "sort=-name,id&limit=2&cursor="+second.Page.PrevCursor)
+	assert.Equal(t, pageNames(page), pageNames(back))
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodGet, "/api/categories?sort=name&cursor="+page.Page.NextCursor, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, `cursor was issued for sort "-name,id"`, body["data"])
+}
+
+func TestCategorySortRejected(t *testing.T) {
+	db := setUpDB()
+	router := setUpRouter(db)
+
+	tests := []struct {
+		sort    string
+		message string
+	}{
+		{"name_key", `cannot sort by "name_key", sortable fields are id, name`},
+		{"name;DROP TABLE category", `cannot sort by "name;DROP TABLE category", sortable fields are id, name`},
+		{"name,-name", `cannot sort by "name" twice`},
+	}
+
+	for _, test := range tests {
+		code, body := sendWithKey(router, "RAHASIA", http.MethodGet, "/api/categories?sort="+url.QueryEscape(test.sort), "")
+		assert.Equal(t, http.StatusBadRequest, code, test.sort)
+		assert.Equal(t, test.message, body["data"], test.sort)
+	}
+}
+
+func TestMemoryCategorySearch(t *testing.T) {
+	ctx := context.Background()
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	for _, name := range searchNames {
+		_, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: name})
+		assert.Nil(t, err)
+	}
+
+	page, err := categoryService.FindPage(ctx, web.CategoryListRequest{NameContains: "BOOK", Sort: "-name", Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, page.Page.Total)
+	assert.Equal(t, "audio books", page.Categories[0].Name)
+	assert.Equal(t, "Books", page.Categories[1].Name)
+
+	page, err = categoryService.FindPage(ctx, web.CategoryListRequest{NameContains: "BOOK", Sort: "-name", Limit: 2, Cursor: page.Page.NextCursor})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(page.Categories))
+	assert.Equal(t, "Bookends", page.Categories[0].Name)
+
+	page, err = categoryService.FindPage(ctx, web.CategoryListRequest{NamePrefix: "5_"})
+	assert.Nil(t, err)
+	assert.Empty(t, page.Categories)
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/config"
+	"golang-restful-api/controller"
+	"golang-restful-api/exception"
+	"golang-restful-api/health"
+	"golang-restful-api/middleware"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCategoryServiceReturnsTypedErrors(t *testing.T) {
+	ctx := context.Background()
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+
+	_, err := categoryService.FindById(ctx, 404)
+	assert.ErrorAs(t, err, &exception.NotFoundError{})
+
+	err = categoryService.Delete(ctx, web.CategoryDeleteRequest{Id: 404})
+	assert.ErrorAs(t, err, &exception.NotFoundError{})
+
+	_, err = categoryService.Create(ctx, web.CategoryCreateRequest{Name: ""})
+	assert.ErrorAs(t, err, &exception.ValidationError{})
+
+	_, err = categoryService.Update(ctx, web.CategoryUpdateRequest{Id: 404, Name: "name_test"})
+	assert.ErrorAs(t, err, &exception.NotFoundError{})
+
+	created, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: "name_test"})
+	assert.Nil(t, err)
+	assert.Equal(t, "name_test", created.Name)
+}
+
+func TestCategoryServiceUnavailableDatabase(t *testing.T) {
+	databaseConfig := config.Default().Database
+	databaseConfig.DSN = "devtest:root@tcp(127.0.0.1:1)/devtest?timeout=1s"
+	dialect := app.NewDialect(databaseConfig)
+	db := app.NewDB(databaseConfig, dialect)
+	defer db.Close()
+
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(dialect), repository.NewAuditRepository(dialect), repository.NewSqlDatabase(db), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	_, err := categoryService.FindAll(context.Background())
+	assert.ErrorAs(t, err, &exception.UnavailableError{})
+
+	healthController := controller.NewHealthController(health.NewHealth(time.Second))
+	router := middleware.NewAuthMiddleware(app.NewRouter(controller.NewCategoryController(categoryService, controller.NewRequestDecoder(1<<20, false)), controller.NewApiKeyController(nil, nil), controller.NewUserController(nil, nil), controller.NewAuditController(nil), healthController), staticKey("RAHASIA"))
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Result().StatusCode)
+}
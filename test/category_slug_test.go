@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func getBySlug(router http.Handler, slug string) (*httptest.ResponseRecorder, web.CategoryResponse) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/by-slug/"+slug, nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	var category web.CategoryResponse
+	_ = json.NewDecoder(recorder.Body).Decode(&web.WebResponse{Data: &category})
+
+	return recorder, category
+}
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Home & Garden":     "home-and-garden",
+		"  Café  Crème!! ":  "cafe-creme",
+		"Straße":            "strasse",
+		"Книги":             "knigi",
+		"Φαγητό":            "fagito",
+		"ﬁle №2":            "file-no2",
+		"Men's -- T-Shirts": "men-s-t-shirts",
+		"!!!":               "",
+	}
+	for text, slug := range cases {
+		assert.Equal(t, slug, service.Slugify(text), text)
+	}
+}
+
+func TestCategorySlug(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	defer truncateCategory(db)
+	router := setUpRouter(db)
+
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Café Crème"}`)
+	assert.Equal(t, http.StatusOK, code)
+	data := created["data"].(map[string]interface{})
+	assert.Equal(t, "cafe-creme", data["slug"])
+	id := int(data["id"].(float64))
+
+	// Another name with the same slug gets the lowest free suffix.
+	code, created = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Cafe-Creme"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "cafe-creme-2", created["data"].(map[string]interface{})["slug"])
+
+	code, created = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "???"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "category", created["data"].(map[string]interface{})["slug"])
+
+	code, created = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Tea", "slug": "Hot Drinks"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "hot-drinks", created["data"].(map[string]interface{})["slug"])
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Coffee", "slug": "hot-drinks"}`)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, `slug "hot-drinks" is already in use`, body["data"].(map[string]interface{})["message"])
+
+	code, body = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Coffee", "slug": "--"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "slug must contain a letter or digit", body["data"])
+
+	recorder, found := getBySlug(router, "cafe-creme")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, id, found.Id)
+
+	recorder, _ = getBySlug(router, "missing")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// A rename moves the category to a new slug, the old one redirects.
+	code, updated := sendWithKey(router, "RAHASIA", http.MethodPut, categoryPath(id, ""), `{"name": "Espresso"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "espresso", updated["data"].(map[string]interface{})["slug"])
+
+	recorder, found = getBySlug(router, "cafe-creme")
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "/api/categories/by-slug/espresso", recorder.Header().Get("Location"))
+	assert.Equal(t, id, found.Id)
+
+	// Former slugs stay reserved for their category.
+	code, created = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Café Crème"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "cafe-creme-3", created["data"].(map[string]interface{})["slug"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Latte", "slug": "cafe-creme"}`)
+	assert.Equal(t, http.StatusConflict, code)
+
+	// ...until the category takes them back.
+	code, updated = sendWithKey(router, "RAHASIA", http.MethodPut, categoryPath(id, ""), `{"name": "Espresso", "slug": "cafe-creme"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "cafe-creme", updated["data"].(map[string]interface{})["slug"])
+
+	recorder, _ = getBySlug(router, "cafe-creme")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	recorder, _ = getBySlug(router, "espresso")
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "/api/categories/by-slug/cafe-creme", recorder.Header().Get("Location"))
+
+	// Deleted categories are not found by slug, purging frees their slugs.
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, categoryPath(id, ""), "")
+	assert.Equal(t, http.StatusOK, code)
+	recorder, _ = getBySlug(router, "espresso")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(id, "/purge"), "")
+	assert.Equal(t, http.StatusOK, code)
+	code, created = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Espresso"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "espresso", created["data"].(map[string]interface{})["slug"])
+}
+
+func TestMemoryCategorySlug(t *testing.T) {
+	ctx := context.Background()
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+
+	books, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: "Books"})
+	assert.Nil(t, err)
+	assert.Equal(t, "books", books.Slug)
+
+	_, err = categoryService.Create(ctx, web.CategoryCreateRequest{Name: "E-Books", Slug: "books"})
+	assert.IsType(t, exception.ConflictError{}, err)
+	ebooks, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: "Books!"})
+	assert.Nil(t, err)
+	assert.Equal(t, "books-2", ebooks.Slug)
+
+	_, err = categoryService.Update(ctx, web.CategoryUpdateRequest{Id: books.Id, Name: "Novels"})
+	assert.Nil(t, err)
+
+	found, moved, err := categoryService.FindBySlug(ctx, "books")
+	assert.Nil(t, err)
+	assert.True(t, moved)
+	assert.Equal(t, "novels", found.Slug)
+
+	found, moved, err = categoryService.FindBySlug(ctx, "books-2")
+	assert.Nil(t, err)
+	assert.False(t, moved)
+	assert.Equal(t, ebooks.Id, found.Id)
+
+	_, _, err = categoryService.FindBySlug(ctx, "comics")
+	assert.IsType(t, exception.NotFoundError{}, err)
+
+	generated, err := categoryService.GenerateMissingSlugs(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, generated)
+}
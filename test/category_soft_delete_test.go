@@ -0,0 +1,165 @@
+package test
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/auth"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func createApiKeyWithScopes(t *testing.T, router http.Handler, scopes string) string {
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/apikeys", `{"name": "limited", "owner": "tests", "scopes": `+scopes+`}`)
+	assert.Equal(t, http.StatusOK, code)
+
+	return created["data"].(map[string]interface{})["key"].(string)
+}
+
+func TestCategoryTimestamps(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	defer truncateCategory(db)
+	router := setUpRouter(db)
+
+	start := time.Now().UTC().Add(-time.Second)
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Gadget"}`)
+	assert.Equal(t, http.StatusOK, code)
+	data := created["data"].(map[string]interface{})
+	id := strconv.Itoa(int(data["id"].(float64)))
+
+	createdAt, err := time.Parse(time.RFC3339Nano, data["created_at"].(string))
+	assert.Nil(t, err)
+	assert.True(t, createdAt.After(start))
+	assert.Equal(t, data["created_at"], data["updated_at"])
+	assert.Nil(t, data["deleted_at"])
+
+	time.Sleep(5 * time.Millisecond)
+	code, updated := sendWithKey(router, "RAHASIA", http.MethodPut, "/api/categories/"+id, `{"name": "Gadgets"}`)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, found := sendWithKey(router, "RAHASIA", http.MethodGet, "/api/categories/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, updated["data"], found["data"])
+	assert.Equal(t, data["created_at"], found["data"].(map[string]interface{})["created_at"])
+
+	updatedAt, err := time.Parse(time.RFC3339Nano, found["data"].(map[string]interface{})["updated_at"].(string))
+	assert.Nil(t, err)
+	assert.True(t, updatedAt.After(createdAt))
+}
+
+func TestCategorySoftDeleteAndRestore(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	defer truncateCategory(db)
+	defer truncateApiKey(db)
+	router := setUpRouter(db)
+
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Gadget"}`)
+	assert.Equal(t, http.StatusOK, code)
+	id := strconv.Itoa(int(created["data"].(map[string]interface{})["id"].(float64)))
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, "/api/categories/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodGet, "/api/categories/"+id, "")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, "/api/categories/"+id, "")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Empty(t, getCategoryPage(t, router, "").Data)
+
+	page := getCategoryPage(t, router, "include_deleted=true")
+	assert.Equal(t, 1, len(page.Data))
+	assert.NotNil(t, page.Data[0].DeletedAt)
+
+	reader := createApiKeyWithScopes(t, router, `["categories:read"]`)
+	code, body := sendWithKey(router, reader, http.MethodGet, "/api/categories?include_deleted=true", "")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "missing scope admin", body["data"])
+
+	// The name of a deleted category is free until it is restored.
+	code, replacement := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "gadget"}`)
+	assert.Equal(t, http.StatusOK, code)
+	replacementId := strconv.Itoa(int(replacement["data"].(map[string]interface{})["id"].(float64)))
+
+	code, body = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories/"+id+"/restore", "")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, `a category named "gadget" already exists`, body["data"].(map[string]interface{})["message"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, "/api/categories/"+replacementId, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, restored := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories/"+id+"/restore", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, restored["data"].(map[string]interface{})["deleted_at"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodGet, "/api/categories/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, body = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories/"+id+"/restore", "")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "category is not deleted", body["data"].(map[string]interface{})["message"])
+}
+
+func TestCategoryPurge(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	defer truncateCategory(db)
+	defer truncateApiKey(db)
+	router := setUpRouter(db)
+
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Gadget"}`)
+	assert.Equal(t, http.StatusOK, code)
+	id := strconv.Itoa(int(created["data"].(map[string]interface{})["id"].(float64)))
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, "/api/categories/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	deleter := createApiKeyWithScopes(t, router, `["categories:delete"]`)
+	code, body := sendWithKey(router, deleter, http.MethodPost, "/api/categories/"+id+"/purge", "")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "missing scope admin", body["data"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories/"+id+"/purge", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories/"+id+"/restore", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Empty(t, getCategoryPage(t, router, "include_deleted=true").Data)
+
+	entries := auditEntries(t, router, "entity_id="+id)
+	assert.Equal(t, "category.purge", entries[0].(map[string]interface{})["action"])
+}
+
+func TestMemoryCategorySoftDelete(t *testing.T) {
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{Type: "api_key", Scopes: []string{auth.ScopeCategoriesRead}})
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+
+	category, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: "Books"})
+	assert.Nil(t, err)
+	assert.Nil(t, categoryService.Delete(ctx, web.CategoryDeleteRequest{Id: category.Id}))
+
+	_, err = categoryService.FindById(ctx, category.Id)
+	assert.IsType(t, exception.NotFoundError{}, err)
+	categories, err := categoryService.FindAll(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, categories)
+
+	_, err = categoryService.FindPage(ctx, web.CategoryListRequest{IncludeDeleted: true})
+	assert.IsType(t, exception.ForbiddenError{}, err)
+
+	restored, err := categoryService.Restore(ctx, category.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, category.CreatedAt, restored.CreatedAt)
+
+	assert.Nil(t, categoryService.Purge(ctx, category.Id))
+	_, err = categoryService.Restore(ctx, category.Id)
+	assert.IsType(t, exception.NotFoundError{}, err)
+}
@@ -0,0 +1,287 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/exception"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// createCategory creates a category below parentId, 0 for a root category,
+// and returns its id.
+func createCategory(t *testing.T, router http.Handler, name string, parentId int) int {
+	body := `{"name": "` + name + `"}`
+	if parentId != 0 {
+		body = `{"name": "` + name + `", "parent_id": ` + strconv.Itoa(parentId) + `}`
+	}
+
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", body)
+	assert.Equal(t, http.StatusOK, code, name)
+
+	return int(created["data"].(map[string]interface{})["id"].(float64))
+}
+
+func categoryPath(id int, suffix string) string {
+	return "/api/categories/" + strconv.Itoa(id) + suffix
+}
+
+func getCategories(t *testing.T, router http.Handler, target string) []string {
+	code, body := sendWithKey(router, "RAHASIA", http.MethodGet, target, "")
+	assert.Equal(t, http.StatusOK, code, target)
+
+	names := []string{}
+	for _, category := range body["data"].([]interface{}) {
+		names = append(names, category.(map[string]interface{})["name"].(string))
+	}
+
+	return names
+}
+
+func getTree(t *testing.T, router http.Handler, target string, tree interface{}) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000"+target, nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code, target)
+
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&web.WebResponse{Data: tree}))
+}
+
+// treeNames flattens trees into "parent/child" paths, depth first.
+func treeNames(prefix string, trees []web.CategoryTreeResponse) []string {
+	var names []string
+	for _, tree := range trees {
+		names = append(names, prefix+tree.Name)
+		names = append(names, treeNames(prefix+tree.Name+"/", tree.Children)...)
+	}
+
+	return names
+}
+
+// setUpTaxonomy creates Electronics > Phones > Smartphones,
+// Electronics > Laptops and Books.
+func setUpTaxonomy(t *testing.T) (http.Handler, map[string]int) {
+	db := setUpDB()
+	truncateCategory(db)
+	truncateAudit(db)
+	t.Cleanup(func() {
+		truncateCategory(db)
+		truncateAudit(db)
+		truncateApiKey(db)
+	})
+	router := setUpRouter(db)
+
+	ids := map[string]int{}
+	ids["Electronics"] = createCategory(t, router, "Electronics", 0)
+	ids["Phones"] = createCategory(t, router, "Phones", ids["Electronics"])
+	ids["Smartphones"] = createCategory(t, router, "Smartphones", ids["Phones"])
+	ids["Laptops"] = createCategory(t, router, "Laptops", ids["Electronics"])
+	ids["Books"] = createCategory(t, router, "Books", 0)
+
+	return router, ids
+}
+
+func TestCategoryTree(t *testing.T) {
+	router, ids := setUpTaxonomy(t)
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodGet, categoryPath(ids["Phones"], ""), "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(ids["Electronics"]), body["data"].(map[string]interface{})["parent_id"])
+	code, body = sendWithKey(router, "RAHASIA", http.MethodGet, categoryPath(ids["Books"], ""), "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, body["data"].(map[string]interface{})["parent_id"])
+
+	assert.Equal(t, []string{"Phones", "Laptops"}, getCategories(t, router, categoryPath(ids["Electronics"], "/children")))
+	assert.Equal(t, []string{}, getCategories(t, router, categoryPath(ids["Books"], "/children")))
+	assert.Equal(t, []string{"Electronics", "Phones"}, getCategories(t, router, categoryPath(ids["Smartphones"], "/ancestors")))
+	assert.Equal(t, []string{}, getCategories(t, router, categoryPath(ids["Electronics"], "/ancestors")))
+
+	var subtree web.CategoryTreeResponse
+	getTree(t, router, categoryPath(ids["Electronics"], "/tree"), &subtree)
+	assert.Equal(t, "Electronics", subtree.Name)
+	assert.Equal(t, []string{"Phones", "Phones/Smartphones", "Laptops"}, treeNames("", subtree.Children))
+
+	var trees []web.CategoryTreeResponse
+	getTree(t, router, "/api/categories/tree", &trees)
+	assert.Equal(t, []string{"Electronics", "Electronics/Phones", "Electronics/Phones/Smartphones", "Electronics/Laptops", "Books"}, treeNames("", trees))
+	assert.NotNil(t, trees[1].Children)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodGet, "/api/categories/404/children", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestCategoryParentRejected(t *testing.T) {
+	router, ids := setUpTaxonomy(t)
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Cases", "parent_id": `+strconv.Itoa(ids["Smartphones"])+`}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "categories cannot be nested more than 3 levels deep", body["data"])
+
+	code, body = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Cases", "parent_id": 404}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "parent category 404 does not exist", body["data"])
+}
+
+func TestCategoryForeignKey(t *testing.T) {
+	db := setUpDB()
+	defer truncateCategory(db)
+
+	_, err := db.Exec("INSERT INTO category(name, parent_id) VALUES ('orphan', 404)")
+	assert.NotNil(t, err)
+}
+
+func TestCategoryMove(t *testing.T) {
+	router, ids := setUpTaxonomy(t)
+
+	code, moved := sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids["Phones"], "/move"), `{"parent_id": `+strconv.Itoa(ids["Books"])+`}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(ids["Books"]), moved["data"].(map[string]interface{})["parent_id"])
+	assert.Equal(t, []string{"Books", "Phones"}, getCategories(t, router, categoryPath(ids["Smartphones"], "/ancestors")))
+	assert.Equal(t, []string{"Laptops"}, getCategories(t, router, categoryPath(ids["Electronics"], "/children")))
+
+	tests := []struct {
+		category string
+		parent   string
+		code     int
+		message  string
+	}{
+		{"Books", "Books", http.StatusConflict, "a category cannot be moved below itself or its descendants"},
+		{"Books", "Smartphones", http.StatusConflict, "a category cannot be moved below itself or its descendants"},
+		{"Phones", "Laptops", http.StatusBadRequest, "categories cannot be nested more than 3 levels deep"},
+	}
+	for _, test := range tests {
+		code, body := sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids[test.category], "/move"), `{"parent_id": `+strconv.Itoa(ids[test.parent])+`}`)
+		assert.Equal(t, test.code, code, test.category+" below "+test.parent)
+		if data, ok := body["data"].(map[string]interface{}); ok {
+			assert.Equal(t, test.message, data["message"])
+		} else {
+			assert.Equal(t, test.message, body["data"])
+		}
+	}
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids["Phones"], "/move"), `{"parent_id": null}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Phones"}, getCategories(t, router, categoryPath(ids["Smartphones"], "/ancestors")))
+
+	reader := createApiKeyWithScopes(t, router, `["categories:read"]`)
+	code, _ = sendWithKey(router, reader, http.MethodPost, categoryPath(ids["Phones"], "/move"), `{"parent_id": null}`)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	entries := auditEntries(t, router, "action=category.move&entity_id="+strconv.Itoa(ids["Phones"]))
+	assert.Equal(t, 2, len(entries))
+}
+
+func TestCategoryDeleteRejectsChildren(t *testing.T) {
+	router, ids := setUpTaxonomy(t)
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodDelete, categoryPath(ids["Electronics"], ""), "")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "category has child categories, move them or delete with children=cascade or children=reparent", body["data"].(map[string]interface{})["message"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, categoryPath(ids["Electronics"], "?children=orphan"), "")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, categoryPath(ids["Laptops"], ""), "")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestCategoryDeleteReparentsChildren(t *testing.T) {
+	router, ids := setUpTaxonomy(t)
+
+	code, _ := sendWithKey(router, "RAHASIA", http.MethodDelete, categoryPath(ids["Phones"], "?children=reparent"), "")
+	assert.Equal(t, http.StatusOK, code)
+
+	assert.Equal(t, []string{"Electronics"}, getCategories(t, router, categoryPath(ids["Smartphones"], "/ancestors")))
+	assert.Equal(t, []string{"Smartphones", "Laptops"}, getCategories(t, router, categoryPath(ids["Electronics"], "/children")))
+}
+
+func TestCategoryDeleteCascades(t *testing.T) {
+	router, ids := setUpTaxonomy(t)
+
+	code, _ := sendWithKey(router, "RAHASIA", http.MethodDelete, categoryPath(ids["Electronics"], "?children=cascade"), "")
+	assert.Equal(t, http.StatusOK, code)
+
+	var trees []web.CategoryTreeResponse
+	getTree(t, router, "/api/categories/tree", &trees)
+	assert.Equal(t, []string{"Books"}, treeNames("", trees))
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodGet, categoryPath(ids["Smartphones"], ""), "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids["Phones"], "/restore"), "")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "parent category is deleted, restore it first", body["data"].(map[string]interface{})["message"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids["Electronics"], "/restore"), "")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids["Phones"], "/restore"), "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Phones"}, getCategories(t, router, categoryPath(ids["Electronics"], "/children")))
+}
+
+func TestCategoryPurgeSubtree(t *testing.T) {
+	router, ids := setUpTaxonomy(t)
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids["Phones"], "/purge"), "")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "category has child categories that are not deleted", body["data"].(map[string]interface{})["message"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodDelete, categoryPath(ids["Phones"], "?children=cascade"), "")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids["Phones"], "/purge"), "")
+	assert.Equal(t, http.StatusOK, code)
+
+	for _, name := range []string{"Phones", "Smartphones"} {
+		code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, categoryPath(ids[name], "/restore"), "")
+		assert.Equal(t, http.StatusNotFound, code, name)
+	}
+	assert.Equal(t, []string{"Laptops"}, getCategories(t, router, categoryPath(ids["Electronics"], "/children")))
+}
+
+func TestMemoryCategoryTree(t *testing.T) {
+	ctx := context.Background()
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+
+	create := func(name string, parentId int) int {
+		category, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: name, ParentId: parentId})
+		assert.Nil(t, err)
+		return category.Id
+	}
+	electronics := create("Electronics", 0)
+	phones := create("Phones", electronics)
+	smartphones := create("Smartphones", phones)
+	books := create("Books", 0)
+
+	trees, err := categoryService.FindTree(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Electronics", "Electronics/Phones", "Electronics/Phones/Smartphones", "Books"}, treeNames("", trees))
+
+	_, err = categoryService.Move(ctx, web.CategoryMoveRequest{Id: electronics, ParentId: smartphones})
+	assert.IsType(t, exception.ConflictError{}, err)
+
+	_, err = categoryService.Move(ctx, web.CategoryMoveRequest{Id: phones, ParentId: books})
+	assert.Nil(t, err)
+	ancestors, err := categoryService.FindAncestors(ctx, smartphones)
+	assert.Nil(t, err)
+	assert.Equal(t, "Books", ancestors[0].Name)
+
+	err = categoryService.Delete(ctx, web.CategoryDeleteRequest{Id: books})
+	assert.IsType(t, exception.ConflictError{}, err)
+	assert.Nil(t, categoryService.Delete(ctx, web.CategoryDeleteRequest{Id: books, Children: "cascade"}))
+
+	trees, err = categoryService.FindTree(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Electronics"}, treeNames("", trees))
+
+	assert.Nil(t, categoryService.Purge(ctx, books))
+	_, err = categoryService.Restore(ctx, smartphones)
+	assert.IsType(t, exception.NotFoundError{}, err)
+}
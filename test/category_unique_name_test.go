@@ -0,0 +1,178 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/domain"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNameRuleKey(t *testing.T) {
+	normalized := service.NameRuleNormalized
+	assert.Equal(t, normalized.Key("café bar"), normalized.Key("  CAFÉ   Bar "))
+	assert.Equal(t, "cafe", normalized.Key("Ｃａｆｅ"))
+	assert.Equal(t, normalized.Key("STRASSE"), normalized.Key("Straße"))
+	assert.NotEqual(t, normalized.Key("cafe"), normalized.Key("café"))
+
+	assert.Equal(t, " Books", service.NameRuleExact.Key(" Books"))
+	assert.Equal(t, "", service.NameRuleOff.Key("Books"))
+}
+
+func sendCategory(router http.Handler, method string, target string, body string) (int, map[string]interface{}) {
+	request := httptest.NewRequest(method, "http://localhost:3000"+target, strings.NewReader(body))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	responseBody, err := io.ReadAll(response.Body)
+	helper.PanicIfError(err)
+
+	var result map[string]interface{}
+	helper.PanicIfError(json.Unmarshal(responseBody, &result))
+
+	return response.StatusCode, result
+}
+
+func TestCreateCategoryDuplicateName(t *testing.T) {
+	db := setUpDB()
+	defer truncateCategory(db)
+	router := setUpRouter(db)
+
+	code, created := sendCategory(router, http.MethodPost, "/api/categories", `{"name": "Books"}`)
+	assert.Equal(t, http.StatusOK, code)
+	id := created["data"].(map[string]interface{})["id"]
+
+	code, conflict := sendCategory(router, http.MethodPost, "/api/categories", `{"name": "  books "}`)
+	assert.Equal(t, http.StatusConflict, code)
+
+	data := conflict["data"].(map[string]interface{})
+	assert.Equal(t, `a category named "Books" already exists`, data["message"])
+	assert.Equal(t, id, data["conflicting"].(map[string]interface{})["id"])
+	assert.Equal(t, "Books", data["conflicting"].(map[string]interface{})["name"])
+
+	code, other := sendCategory(router, http.MethodPost, "/api/categories", `{"name": "Music"}`)
+	assert.Equal(t, http.StatusOK, code)
+	otherId := int(other["data"].(map[string]interface{})["id"].(float64))
+
+	code, _ = sendCategory(router, http.MethodPut, "/api/categories/"+strconv.Itoa(otherId), `{"name": "BOOKS"}`)
+	assert.Equal(t, http.StatusConflict, code)
+
+	code, _ = sendCategory(router, http.MethodPut, "/api/categories/"+strconv.Itoa(int(id.(float64))), `{"name": "BOOKS"}`)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestCategoryRepositoryDuplicateKey(t *testing.T) {
+	db := setUpDB()
+	defer truncateCategory(db)
+	ctx := context.Background()
+
+	repositories := map[string]struct {
+		repository repository.CategoryRepository
+		database   repository.Database
+	}{
+		"sql":    {repository.NewCategoryRepository(setUpDialect()), repository.NewSqlDatabase(db)},
+		"memory": {repository.NewMemoryCategoryRepository(), repository.NewMemoryDatabase()},
+	}
+
+	for name, test := range repositories {
+		t.Run(name, func(t *testing.T) {
+			tx, err := test.database.Begin(ctx)
+			assert.Nil(t, err)
+			defer tx.Rollback()
+
+			_, err = test.repository.Save(ctx, tx, domain.Category{Name: "Books", NameKey: "books"})
+			assert.Nil(t, err)
+
+			_, err = test.repository.Save(ctx, tx, domain.Category{Name: "BOOKS", NameKey: "books"})
+			assert.ErrorAs(t, err, &exception.ConflictError{})
+
+			// Without a key names are not unique.
+			_, err = test.repository.Save(ctx, tx, domain.Category{Name: "Books"})
+			assert.Nil(t, err)
+			_, err = test.repository.Save(ctx, tx, domain.Category{Name: "Books"})
+			assert.Nil(t, err)
+		})
+	}
+}
+
+// racingCategoryRepository misses the first name lookup, as if the
+// conflicting category had been committed right after it.
+type racingCategoryRepository struct {
+	repository.CategoryRepository
+	lookups int
+}
+
+func (repository *racingCategoryRepository) FindByNameKey(ctx context.Context, tx repository.Tx, nameKey string) (domain.Category, error) {
+	repository.lookups++
+	if repository.lookups == 1 {
+		return domain.Category{}, exception.NewNotFoundError("category not found")
+	}
+
+	return repository.CategoryRepository.FindByNameKey(ctx, tx, nameKey)
+}
+
+func TestCreateCategoryDuplicateCaughtByIndex(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	defer truncateCategory(db)
+	ctx := context.Background()
+
+	categoryRepository := repository.NewCategoryRepository(setUpDialect())
+	database := repository.NewSqlDatabase(db)
+	categoryService := service.NewCategoryService(categoryRepository, repository.NewAuditRepository(setUpDialect()), database, validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	existing, err := categoryService.Create(ctx, createRequest("Books"))
+	assert.Nil(t, err)
+
+	racingService := service.NewCategoryService(&racingCategoryRepository{CategoryRepository: categoryRepository}, repository.NewAuditRepository(setUpDialect()), database, validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	_, err = racingService.Create(ctx, web.CategoryCreateRequest{Name: "BOOKS", Slug: "other-books"})
+
+	var conflict exception.ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.Equal(t, `a category named "Books" already exists`, conflict.Message)
+	assert.Equal(t, existing, conflict.Conflicting)
+}
+
+func createRequest(name string) web.CategoryCreateRequest {
+	return web.CategoryCreateRequest{Name: name}
+}
+
+func TestRebuildNameKeys(t *testing.T) {
+	ctx := context.Background()
+	categoryRepository := repository.NewMemoryCategoryRepository()
+	database := repository.NewMemoryDatabase()
+
+	exact := service.NewCategoryService(categoryRepository, repository.NewMemoryAuditRepository(), database, validator.New(), service.NameRuleExact, testPageSize, testHierarchy)
+	_, err := exact.Create(ctx, createRequest("Books"))
+	assert.Nil(t, err)
+	_, err = exact.Create(ctx, createRequest("Music"))
+	assert.Nil(t, err)
+
+	normalized := service.NewCategoryService(categoryRepository, repository.NewMemoryAuditRepository(), database, validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	updated, err := normalized.RebuildNameKeys(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, updated)
+
+	_, err = normalized.Create(ctx, createRequest(" BOOKS"))
+	assert.ErrorAs(t, err, &exception.ConflictError{})
+
+	_, err = exact.Create(ctx, createRequest("MUSIC"))
+	assert.Nil(t, err)
+
+	_, err = normalized.RebuildNameKeys(ctx)
+	assert.ErrorAs(t, err, &exception.ConflictError{})
+}
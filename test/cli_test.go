@@ -0,0 +1,150 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/cli"
+	"golang-restful-api/model/web"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCli(t *testing.T, dsn string, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-database.driver", "sqlite", "-database.dsn", dsn}, args...)
+
+	code := cli.Run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCliSeedExportImport(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "cli.db")
+
+	code, _, stderr := runCli(t, dsn, "", "migrate", "up")
+	assert.Equal(t, cli.ExitOK, code, stderr)
+
+	code, stdout, _ := runCli(t, dsn, "", "seed")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "seeded")
+
+	code, stdout, _ = runCli(t, dsn, "", "seed")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "nothing seeded")
+
+	code, stdout, stderr = runCli(t, dsn, "name\nGarden\n\"\"\n", "categories", "import", "-format", "csv")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, "record 2")
+
+	code, _, stderr = runCli(t, dsn, `[{"name": "Jazz"}, {"name": " JAZZ"}]`, "categories", "import")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, "duplicates record 1")
+
+	code, stdout, _ = runCli(t, dsn, `[{"name": "Garden"}, {"name": "Music"}]`, "categories", "import")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "imported 2 categories")
+
+	code, stdout, _ = runCli(t, dsn, "", "categories", "export")
+	assert.Equal(t, cli.ExitOK, code)
+
+	var categories []web.CategoryResponse
+	assert.Nil(t, json.Unmarshal([]byte(stdout), &categories))
+	assert.Len(t, categories, 8)
+	assert.Equal(t, "Music", categories[7].Name)
+
+	code, stdout, _ = runCli(t, dsn, "", "seed", "-force")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "seeded 0 categories")
+
+	code, stdout, _ = runCli(t, dsn, "", "-category.unique_names", "exact", "categories", "reindex")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "reindexed 8 categories")
+}
+
+func exportCategoryRecords(t *testing.T, dsn string) []map[string]string {
+	code, stdout, stderr := runCli(t, dsn, "", "categories", "export")
+	assert.Equal(t, cli.ExitOK, code, stderr)
+
+	var records []map[string]string
+	assert.Nil(t, json.Unmarshal([]byte(stdout), &records))
+
+	return records
+}
+
+func TestCliExportImportTree(t *testing.T) {
+	source := "file:" + filepath.Join(t.TempDir(), "source.db")
+	target := "file:" + filepath.Join(t.TempDir(), "target.db")
+	for _, dsn := range []string{source, target} {
+		code, _, stderr := runCli(t, dsn, "", "migrate", "up")
+		assert.Equal(t, cli.ExitOK, code, stderr)
+	}
+
+	// Parents are referred to by slug and must be part of the same import.
+	code, _, stderr := runCli(t, source, `[{"name": "Root", "slug": "root"}, {"name": "Child", "slug": "child", "parent": "root"}]`, "categories", "import")
+	assert.Equal(t, cli.ExitOK, code, stderr)
+	code, _, stderr = runCli(t, source, `[{"name": "Grandchild", "parent": "child"}]`, "categories", "import")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, `parent "child" is not the slug of an earlier record`)
+
+	code, _, stderr = runCli(t, source, `[{"name": "Grandchild", "parent_id": 2}]`, "categories", "import")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, "parent_id cannot be imported")
+
+	code, _, stderr = runCli(t, source, "name,parent_id\nGrandchild,2\n", "categories", "import", "-format", "csv")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, "parent_id cannot be imported")
+
+	exported := exportCategoryRecords(t, source)
+	assert.Equal(t, []map[string]string{
+		{"name": "Root", "slug": "root"},
+		{"name": "Child", "slug": "child", "parent": "root"},
+	}, exported)
+
+	// The target has categories with the ids the source used.
+	code, _, _ = runCli(t, target, "", "seed")
+	assert.Equal(t, cli.ExitOK, code)
+
+	input, err := json.Marshal(exported)
+	assert.Nil(t, err)
+	code, stdout, stderr := runCli(t, target, string(input), "categories", "import")
+	assert.Equal(t, cli.ExitOK, code, stderr)
+	assert.Contains(t, stdout, "imported 2 categories")
+
+	records := exportCategoryRecords(t, target)
+	assert.Equal(t, map[string]string{"name": "Root", "slug": "root"}, records[len(records)-2])
+	assert.Equal(t, map[string]string{"name": "Child", "slug": "child", "parent": "root"}, records[len(records)-1])
+	for _, record := range records[:len(records)-2] {
+		assert.Empty(t, record["parent"], record["name"])
+	}
+
+	code, stdout, _ = runCli(t, target, "", "categories", "export", "-format", "csv")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.True(t, strings.HasPrefix(stdout, "name,slug,parent\n"))
+	assert.True(t, strings.HasSuffix(stdout, "Root,root,\nChild,child,root\n"))
+
+	copied := "file:" + filepath.Join(t.TempDir(), "copy.db")
+	code, _, _ = runCli(t, copied, "", "migrate", "up")
+	assert.Equal(t, cli.ExitOK, code)
+	code, _, stderr = runCli(t, copied, stdout, "categories", "import", "-format", "csv")
+	assert.Equal(t, cli.ExitOK, code, stderr)
+	assert.Equal(t, records, exportCategoryRecords(t, copied))
+}
+
+func TestCliExitCodes(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "cli.db")
+
+	code, _, _ := runCli(t, dsn, "", "unknown")
+	assert.Equal(t, cli.ExitUsage, code)
+
+	code, _, _ = runCli(t, dsn, "", "migrate", "down", "zero")
+	assert.Equal(t, cli.ExitUsage, code)
+
+	code, _, _ = runCli(t, dsn, "", "-server.shutdown_timeout", "0s", "migrate", "status")
+	assert.Equal(t, cli.ExitConfig, code)
+
+	code, stdout, _ := runCli(t, dsn, "", "apikeys", "generate")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Len(t, strings.TrimSpace(stdout), 43)
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/config"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func loadConfig(t *testing.T, args ...string) (*config.Config, error) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	loader := config.NewLoader(flags)
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.Load()
+}
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0600)
+	assert.Nil(t, err)
+
+	return path
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg, err := loadConfig(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost:3000", cfg.Server.Address)
+	assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+	assert.Equal(t, "", cfg.Auth.APIKey)
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  address: "0.0.0.0:8080"
+  read_timeout: 30s
+database:
+  dsn: "file-dsn"
+  max_open_conns: 10
+`)
+	t.Setenv("APP_DATABASE_MAX_OPEN_CONNS", "20")
+	t.Setenv("APP_AUTH_API_KEY", "env-key")
+
+	cfg, err := loadConfig(t, "-config", path, "-auth.api_key", "flag-key")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0.0.0.0:8080", cfg.Server.Address)
+	assert.Equal(t, 30*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, "file-dsn", cfg.Database.DSN)
+	assert.Equal(t, 20, cfg.Database.MaxOpenConns)
+	assert.Equal(t, "flag-key", cfg.Auth.APIKey)
+}
+
+func TestConfigFlagsSkipMaps(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.NewLoader(flags)
+
+	assert.NotNil(t, flags.Lookup("rate_limit.default.requests"))
+	assert.NotNil(t, flags.Lookup("auth.users.registration_scopes"))
+	for _, name := range []string{"auth.client_certs", "rate_limit.keys", "rate_limit.routes"} {
+		assert.Nil(t, flags.Lookup(name), name)
+	}
+}
+
+func TestConfigJSONFile(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"server": {"address": ":9000", "idle_timeout": "2m"}}`)
+	t.Setenv("APP_CONFIG", path)
+
+	cfg, err := loadConfig(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, ":9000", cfg.Server.Address)
+	assert.Equal(t, 2*time.Minute, cfg.Server.IdleTimeout)
+}
+
+func TestConfigInvalid(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "server:\n  adress: \":9000\"\n")
+	_, err := loadConfig(t, "-config", path)
+	assert.NotNil(t, err)
+
+	_, err = loadConfig(t, "-database.driver", "oracle")
+	assert.NotNil(t, err)
+
+	_, err = loadConfig(t, "-database.max_open_conns", "many")
+	assert.NotNil(t, err)
+
+	_, err = loadConfig(t, "-reporter.type", "file")
+	assert.NotNil(t, err)
+
+	_, err = loadConfig(t, "-reporter.type", "http", "-reporter.url", "not a url")
+	assert.NotNil(t, err)
+
+	_, err = loadConfig(t, "-server.tls.enabled", "true")
+	assert.NotNil(t, err)
+
+	_, err = loadConfig(t, "-server.tls.enabled", "true", "-server.tls.cert_file", "server.crt", "-server.tls.key_file", "server.key", "-server.tls.client_auth", "require")
+	assert.EqualError(t, err, "config: invalid configuration: server.tls.client_auth require needs a client_ca_file")
+}
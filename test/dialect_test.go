@@ -0,0 +1,21 @@
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/repository"
+	"testing"
+)
+
+func TestDialectRebind(t *testing.T) {
+	postgres, err := repository.NewDialect("postgres")
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE category SET name = $1 WHERE id = $2 AND note <> '?'",
+		postgres.Rebind("UPDATE category SET name = ? WHERE id = ? AND note <> '?'"))
+
+	mysql, err := repository.NewDialect("mysql")
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id FROM category WHERE id = ?", mysql.Rebind("SELECT id FROM category WHERE id = ?"))
+
+	_, err = repository.NewDialect("oracle")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/helper"
+	"golang-restful-api/middleware"
+	"golang-restful-api/reporter"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingReporter struct {
+	mutex   sync.Mutex
+	reports []reporter.Report
+}
+
+func (recorder *recordingReporter) Report(ctx context.Context, report reporter.Report) error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.reports = append(recorder.reports, report)
+	return nil
+}
+
+func TestInternalErrorIsSanitizedAndReported(t *testing.T) {
+	db := setUpDB()
+	errorReporter := &recordingReporter{}
+	router := middleware.NewRequestIdMiddleware(middleware.NewReporterMiddleware(setUpRouter(db), errorReporter))
+	helper.PanicIfError(db.Close())
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	request.Header.Add("X-Request-ID", "req-123")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	assert.Equal(t, "req-123", response.Header.Get("X-Request-ID"))
+
+	body, errReadAll := io.ReadAll(response.Body)
+	helper.PanicIfError(errReadAll)
+	assert.NotContains(t, string(body), "sql")
+
+	var responseBody map[string]interface{}
+	errUnmarshal := json.Unmarshal(body, &responseBody)
+	helper.PanicIfError(errUnmarshal)
+
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, "internal server error", data["message"])
+
+	assert.Len(t, errorReporter.reports, 1)
+	report := errorReporter.reports[0]
+	assert.Equal(t, data["error_id"], report.ErrorId)
+	assert.Equal(t, "req-123", report.RequestId)
+	assert.Equal(t, http.MethodGet, report.Method)
+	assert.Equal(t, "/api/categories", report.Path)
+	assert.Contains(t, report.Error, "sql: database is closed")
+	assert.NotEmpty(t, report.Stack)
+}
+
+func TestRequestIdGeneratedWhenMissingOrInvalid(t *testing.T) {
+	handler := middleware.NewRequestIdMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, writer.Header().Get("X-Request-ID"), helper.RequestIdFromContext(request.Context()))
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/healthz", nil)
+	request.Header.Add("X-Request-ID", "bad id\n")
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	assert.Len(t, recorder.Header().Get("X-Request-ID"), 32)
+}
+
+func TestFileReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	fileReporter, err := reporter.NewFileReporter(path)
+	assert.Nil(t, err)
+
+	err = fileReporter.Report(context.Background(), reporter.Report{ErrorId: "1", Error: "boom"})
+	assert.Nil(t, err)
+	err = fileReporter.Report(context.Background(), reporter.Report{ErrorId: "2", Error: "bang"})
+	assert.Nil(t, err)
+	assert.Nil(t, fileReporter.Close())
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+
+	var report reporter.Report
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &report))
+	assert.Equal(t, "2", report.ErrorId)
+	assert.Equal(t, "bang", report.Error)
+}
+
+func TestHTTPReporter(t *testing.T) {
+	received := make(chan reporter.Report, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var report reporter.Report
+		helper.PanicIfError(json.NewDecoder(request.Body).Decode(&report))
+		received <- report
+		writer.WriteHeader(http.StatusAccepted)
+	}))
+	defer collector.Close()
+
+	err := reporter.NewHTTPReporter(collector.URL, time.Second).Report(context.Background(), reporter.Report{ErrorId: "abc", Error: "boom"})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", (<-received).ErrorId)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	err = reporter.NewHTTPReporter(failing.URL, time.Second).Report(context.Background(), reporter.Report{ErrorId: "abc"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,110 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/controller"
+	"golang-restful-api/health"
+	"golang-restful-api/helper"
+	"golang-restful-api/middleware"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func setUpHealthRouter(checks *health.Health) http.Handler {
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	router := app.NewRouter(controller.NewCategoryController(categoryService, controller.NewRequestDecoder(1<<20, false)), controller.NewApiKeyController(nil, nil), controller.NewUserController(nil, nil), controller.NewAuditController(nil), controller.NewHealthController(checks))
+
+	return middleware.NewAuthMiddleware(router, staticKey("RAHASIA"), app.PublicPaths...)
+}
+
+func getHealth(handler http.Handler, path string) (int, map[string]interface{}) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000"+path, nil)
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	body, errReadAll := io.ReadAll(response.Body)
+	helper.PanicIfError(errReadAll)
+
+	var responseBody map[string]interface{}
+	errUnmarshal := json.Unmarshal(body, &responseBody)
+	helper.PanicIfError(errUnmarshal)
+
+	return response.StatusCode, responseBody
+}
+
+func TestHealthEndpointsAreUnauthenticated(t *testing.T) {
+	db := setUpDB()
+	checks := health.NewHealth(time.Second)
+	checks.Register(health.NewDatabaseChecker(db))
+	handler := setUpHealthRouter(checks)
+
+	code, body := getHealth(handler, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusUp, body["data"].(map[string]interface{})["status"])
+
+	code, body = getHealth(handler, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	checksBody := body["data"].(map[string]interface{})["checks"].(map[string]interface{})
+	assert.Equal(t, health.StatusUp, checksBody["database"].(map[string]interface{})["status"])
+
+	code, _ = getHealth(handler, "/api/categories")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestReadinessReportsFailingChecks(t *testing.T) {
+	shutdown := app.NewShutdown(time.Second)
+	checks := health.NewHealth(50 * time.Millisecond)
+	checks.Register(health.NewShutdownChecker(shutdown))
+	checks.Register(health.NewChecker("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	checks.Register(health.NewChecker("broken", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}))
+	handler := setUpHealthRouter(checks)
+
+	assert.Nil(t, shutdown.Run())
+	output := captureLog(t)
+	code, body := getHealth(handler, "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, health.StatusDown, data["status"])
+
+	checksBody := data["checks"].(map[string]interface{})
+	for _, name := range []string{"broken", "slow", "shutdown"} {
+		assert.Equal(t, "unavailable", checksBody[name].(map[string]interface{})["error"], name)
+	}
+	assert.Contains(t, output.String(), "health: broken: connection refused")
+	assert.Contains(t, output.String(), "health: slow: context deadline exceeded")
+	assert.Contains(t, output.String(), "health: shutdown: server is shutting down")
+}
+
+func TestMigrationCheckerOnlyReads(t *testing.T) {
+	migrator, db := setUpMigrator(t)
+	checker := health.NewMigrationChecker(migrator)
+
+	err := checker.Check(context.Background())
+	assert.EqualError(t, err, fmt.Sprintf("schema is at version 0, expected %d", migrator.Latest()))
+
+	var tables int
+	assert.Nil(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&tables))
+	assert.Equal(t, 0, tables)
+
+	assert.Nil(t, migrator.Up(context.Background()))
+	assert.Nil(t, checker.Check(context.Background()))
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/auth"
+	"golang-restful-api/config"
+	"golang-restful-api/middleware"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setUpJWTHandler(t *testing.T, jwtConfig config.JWTConfig) (http.Handler, *auth.Identity) {
+	authenticator, err := auth.NewJWTAuthenticator(jwtConfig)
+	assert.Nil(t, err)
+
+	identity := &auth.Identity{}
+	handler := middleware.NewAuthMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		*identity, _ = auth.IdentityFromContext(request.Context())
+	}), []auth.Authenticator{authenticator})
+
+	return handler, identity
+}
+
+func sendBearer(handler http.Handler, token string) *http.Response {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	return recorder.Result()
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	assert.Nil(t, err)
+
+	return signed
+}
+
+func TestJWTHS256Claims(t *testing.T) {
+	secret := []byte("shared-secret")
+	handler, identity := setUpJWTHandler(t, config.JWTConfig{
+		Secret:   string(secret),
+		Issuer:   "https://auth.internal",
+		Audience: "categories",
+		Leeway:   30 * time.Second,
+	})
+
+	now := time.Now()
+	claims := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"sub":   "billing-service",
+			"iss":   "https://auth.internal",
+			"aud":   []string{"categories", "orders"},
+			"exp":   now.Add(time.Minute).Unix(),
+			"scope": "categories:read categories:write",
+			"team":  "billing",
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+			} else {
+				claims[name] = value
+			}
+		}
+		return claims
+	}
+
+	response := sendBearer(handler, signToken(t, jwt.SigningMethodHS256, secret, "", claims(nil)))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "jwt", identity.Type)
+	assert.Equal(t, "billing-service", identity.Subject)
+	assert.Equal(t, []string{"categories:read", "categories:write"}, identity.Scopes)
+	assert.Equal(t, "billing", identity.Claims["team"])
+
+	// Expired, but within the tolerated clock skew.
+	response = sendBearer(handler, signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()})))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	rejected := map[string]string{
+		"expired":       signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()})),
+		"not yet valid": signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"nbf": now.Add(time.Minute).Unix()})),
+		"no expiry":     signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": nil})),
+		"issuer":        signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"iss": "https://evil"})),
+		"audience":      signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"aud": "orders"})),
+		"no subject":    signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"sub": nil})),
+		"wrong secret":  signToken(t, jwt.SigningMethodHS256, []byte("other"), "", claims(nil)),
+		"unsigned":      signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claims(nil)),
+		"garbage":       "not.a.token",
+	}
+	for name, token := range rejected {
+		response := sendBearer(handler, token)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode, name)
+		assert.Equal(t, `Bearer realm="api"`, response.Header.Get("WWW-Authenticate"), name)
+	}
+}
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PublicKey, ecKey *ecdsa.PublicKey) string {
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	content, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kid": "rsa-1", "kty": "RSA", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+			{"kid": "ec-1", "kty": "EC", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+		},
+	})
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.Nil(t, os.WriteFile(path, content, 0600))
+
+	return path
+}
+
+func TestJWTJWKSKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	handler, identity := setUpJWTHandler(t, config.JWTConfig{JWKSFile: writeJWKS(t, &rsaKey.PublicKey, &ecKey.PublicKey)})
+
+	claims := jwt.MapClaims{"sub": "orders", "name": "Orders service", "scp": []string{"categories:read"}, "exp": time.Now().Add(time.Minute).Unix()}
+
+	response := sendBearer(handler, signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", claims))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "Orders service", identity.Name)
+	assert.Equal(t, []string{"categories:read"}, identity.Scopes)
+
+	response = sendBearer(handler, signToken(t, jwt.SigningMethodES256, ecKey, "ec-1", claims))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// The RSA key must not verify an ES256 token, nor an unknown key id.
+	response = sendBearer(handler, signToken(t, jwt.SigningMethodES256, ecKey, "rsa-1", claims))
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	response = sendBearer(handler, signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-2", claims))
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// Without a shared secret HS256 tokens are not accepted at all.
+	response = sendBearer(handler, signToken(t, jwt.SigningMethodHS256, []byte(""), "", claims))
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestJWTConfigRequiresKey(t *testing.T) {
+	_, err := loadConfig(t, "-auth.jwt.enabled", "true")
+	assert.NotNil(t, err)
+
+	cfg, err := loadConfig(t, "-auth.jwt.enabled", "true", "-auth.jwt.secret", "s")
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, cfg.Auth.JWT.Leeway)
+
+	_, err = auth.NewJWTAuthenticator(config.JWTConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/config"
+	"golang-restful-api/helper"
+	"golang-restful-api/migration"
+	"path/filepath"
+	"testing"
+)
+
+func setUpMigrator(t *testing.T) (*migration.Migrator, *sql.DB) {
+	databaseConfig := config.Default().Database
+	databaseConfig.Driver = "sqlite"
+	databaseConfig.DSN = "file:" + filepath.Join(t.TempDir(), "migration.db")
+
+	dialect := app.NewDialect(databaseConfig)
+	db := app.NewDB(databaseConfig, dialect)
+	t.Cleanup(func() {
+		helper.PanicIfError(db.Close())
+	})
+
+	migrator, err := migration.NewMigrator(db, dialect)
+	helper.PanicIfError(err)
+
+	return migrator, db
+}
+
+func TestMigrationUpDownAndStatus(t *testing.T) {
+	ctx := context.Background()
+	migrator, db := setUpMigrator(t)
+
+	version, err := migrator.Version(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, version)
+	statuses, err := migrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.False(t, statuses[0].Applied)
+
+	// Reading the version does not create the version table.
+	var tables int
+	assert.Nil(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&tables))
+	assert.Equal(t, 0, tables)
+
+	assert.Nil(t, migrator.Up(ctx))
+	assert.Nil(t, migrator.Up(ctx))
+
+	version, err = migrator.Version(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, migrator.Latest(), version)
+
+	_, err = db.Exec("INSERT INTO category(name) VALUES ('migrated')")
+	assert.Nil(t, err)
+
+	statuses, err = migrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, statuses, len(migrator.Migrations))
+	assert.True(t, statuses[0].Applied)
+
+	assert.Nil(t, migrator.Goto(ctx, 0))
+	_, err = db.Exec("SELECT id FROM category")
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, migrator.Goto(ctx, 9999))
+}
+
+func TestMigrationChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	migrator, _ := setUpMigrator(t)
+	assert.Nil(t, migrator.Up(ctx))
+
+	migrator.Migrations[0].Checksum = "edited"
+
+	_, err := migrator.Status(ctx)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.ErrorContains(t, migrator.Up(ctx), "checksum mismatch")
+}
@@ -0,0 +1,181 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+type categoryPage struct {
+	Code int                    `json:"code"`
+	Data []web.CategoryResponse `json:"data"`
+	Page web.PageResponse       `json:"page"`
+	Link string                 `json:"-"`
+}
+
+func getCategoryPage(t *testing.T, router http.Handler, query string) categoryPage {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?"+query, nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	page := categoryPage{Link: recorder.Header().Get("Link")}
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&page))
+
+	return page
+}
+
+func pageNames(page categoryPage) []string {
+	var names []string
+	for _, category := range page.Data {
+		names = append(names, category.Name)
+	}
+
+	return names
+}
+
+func createCategories(t *testing.T, router http.Handler, count int) {
+	for i := 1; i <= count; i++ {
+		code, _ := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/categories", `{"name": "Category `+strconv.Itoa(i)+`"}`)
+		assert.Equal(t, http.StatusOK, code)
+	}
+}
+
+func TestCategoryPageByCursor(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	defer truncateCategory(db)
+	router := setUpRouter(db)
+	createCategories(t, router, 7)
+
+	first := getCategoryPage(t, router, "")
+	assert.Equal(t, []string{"Category 1", "Category 2", "Category 3"}, pageNames(first))
+	assert.Equal(t, web.PageResponse{Total: 7, Limit: 3, NextCursor: first.Page.NextCursor}, first.Page)
+	assert.NotEmpty(t, first.Page.NextCursor)
+
+	second := getCategoryPage(t, router, "cursor="+first.Page.NextCursor)
+	assert.Equal(t, []string{"Category 4", "Category 5", "Category 6"}, pageNames(second))
+	assert.NotEmpty(t, second.Page.PrevCursor)
+
+	last := getCategoryPage(t, router, "cursor="+second.Page.NextCursor)
+	assert.Equal(t, []string{"Category 7"}, pageNames(last))
+	assert.Empty(t, last.Page.NextCursor)
+
+	previous := getCategoryPage(t, router, "cursor="+last.Page.PrevCursor)
+	assert.Equal(t, pageNames(second), pageNames(previous))
+
+	previous = getCategoryPage(t, router, "cursor="+previous.Page.PrevCursor)
+	assert.Equal(t, pageNames(first), pageNames(previous))
+	assert.Empty(t, previous.Page.PrevCursor)
+	assert.NotEmpty(t, previous.Page.NextCursor)
+
+	// Deleting an item already seen neither skips nor repeats any.
+	code, _ := sendWithKey(router, "RAHASIA", http.MethodDelete, "/api/categories/"+strconv.Itoa(first.Data[0].Id), "")
+	assert.Equal(t, http.StatusOK, code)
+	second = getCategoryPage(t, router, "cursor="+first.Page.NextCursor)
+	assert.Equal(t, []string{"Category 4", "Category 5", "Category 6"}, pageNames(second))
+	assert.Equal(t, 6, second.Page.Total)
+}
+
+func TestCategoryPageByOffset(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	defer truncateCategory(db)
+	router := setUpRouter(db)
+	createCategories(t, router, 7)
+
+	page := getCategoryPage(t, router, "limit=2&offset=5")
+	assert.Equal(t, []string{"Category 6", "Category 7"}, pageNames(page))
+	assert.Equal(t, 2, page.Page.Limit)
+	assert.Empty(t, page.Page.NextCursor)
+	assert.NotEmpty(t, page.Page.PrevCursor)
+
+	page = getCategoryPage(t, router, "limit=5&offset=10")
+	assert.Empty(t, page.Data)
+	assert.Equal(t, 7, page.Page.Total)
+}
+
+func TestCategoryPageLinks(t *testing.T) {
+	db := setUpDB()
+	truncateCategory(db)
+	defer truncateCategory(db)
+	router := setUpRouter(db)
+	createCategories(t, router, 5)
+
+	page := getCategoryPage(t, router, "limit=2&offset=2")
+
+	links := map[string]string{}
+	for _, match := range regexp.MustCompile(`<([^>]*)>; rel="(\w+)"`).FindAllStringSubmatch(page.Link, -1) {
+		links[match[2]] = match[1]
+	}
+
+	assert.Equal(t, "/api/categories?limit=2", links["first"])
+	assert.Equal(t, "/api/categories?cursor="+page.Page.PrevCursor+"&limit=2", links["prev"])
+	assert.Equal(t, "/api/categories?cursor="+page.Page.NextCursor+"&limit=2", links["next"])
+
+	next, err := url.Parse(links["next"])
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Category 5"}, pageNames(getCategoryPage(t, router, next.RawQuery)))
+}
+
+func TestCategoryPageRejected(t *testing.T) {
+	db := setUpDB()
+	router := setUpRouter(db)
+
+	tests := []struct {
+		query   string
+		message interface{}
+	}{
+		{"limit=6", "limit must not exceed 5"},
+		{"limit=two", "limit must be an integer"},
+		{"cursor=bm90IGEgY3Vyc29y", "cursor is invalid"},
+		{"cursor=eyJhIjoxfQ&offset=3", "offset cannot be combined with cursor"},
+	}
+
+	for _, test := range tests {
+		code, body := sendWithKey(router, "RAHASIA", http.MethodGet, "/api/categories?"+test.query, "")
+		assert.Equal(t, http.StatusBadRequest, code, test.query)
+		assert.Equal(t, test.message, body["data"], test.query)
+	}
+
+	code, _ := sendWithKey(router, "RAHASIA", http.MethodGet, "/api/categories?offset=-1", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestMemoryCategoryPage(t *testing.T) {
+	ctx := context.Background()
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		_, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: name})
+		assert.Nil(t, err)
+	}
+
+	first, err := categoryService.FindPage(ctx, web.CategoryListRequest{Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(first.Categories))
+	assert.Equal(t, 5, first.Page.Total)
+
+	second, err := categoryService.FindPage(ctx, web.CategoryListRequest{Limit: 2, Cursor: first.Page.NextCursor})
+	assert.Nil(t, err)
+	assert.Equal(t, "C", second.Categories[0].Name)
+
+	back, err := categoryService.FindPage(ctx, web.CategoryListRequest{Limit: 2, Cursor: second.Page.PrevCursor})
+	assert.Nil(t, err)
+	assert.Equal(t, first.Categories, back.Categories)
+
+	last, err := categoryService.FindPage(ctx, web.CategoryListRequest{Limit: 2, Offset: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, "E", last.Categories[0].Name)
+	assert.Empty(t, last.Page.NextCursor)
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/auth"
+	"golang-restful-api/exception"
+	"golang-restful-api/middleware"
+	"net/http"
+	"testing"
+)
+
+func TestPolicyAuthorize(t *testing.T) {
+	reader := auth.WithIdentity(context.Background(), auth.Identity{Type: "api_key", Scopes: []string{auth.ScopeCategoriesRead}})
+	admin := auth.WithIdentity(context.Background(), auth.Identity{Type: "api_key", Scopes: []string{auth.ScopeAdmin}})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		method  string
+		pattern string
+		allowed bool
+	}{
+		{"read may list", reader, http.MethodGet, "/api/categories", true},
+		{"read may get", reader, http.MethodGet, "/api/categories/:categoryId", true},
+		{"read may not create", reader, http.MethodPost, "/api/categories", false},
+		{"read may not delete", reader, http.MethodDelete, "/api/categories/:categoryId", false},
+		{"read may not manage keys", reader, http.MethodGet, "/api/apikeys", false},
+		{"admin may manage keys", admin, http.MethodPost, "/api/apikeys", true},
+		{"admin has no category scopes", admin, http.MethodGet, "/api/categories", false},
+		{"health needs no scope", context.Background(), http.MethodGet, "/healthz", true},
+		{"undeclared route is denied", admin, http.MethodPatch, "/api/categories/:categoryId", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := app.Policy.Authorize(test.ctx, test.method, test.pattern)
+			if test.allowed {
+				assert.Nil(t, err)
+				return
+			}
+
+			_, ok := err.(exception.ForbiddenError)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestPolicyNamesMissingScope(t *testing.T) {
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{Scopes: []string{auth.ScopeCategoriesRead}})
+
+	err := app.Policy.Authorize(ctx, http.MethodDelete, "/api/categories/:categoryId")
+
+	assert.Equal(t, "missing scope categories:delete", err.Error())
+}
+
+func TestAuthorizePanicsOnUndeclaredRoute(t *testing.T) {
+	handle := func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {}
+
+	assert.Panics(t, func() {
+		middleware.Authorize(app.Policy, http.MethodGet, "/api/undeclared", handle)
+	})
+}
+
+func TestPolicyEnforcedByRouter(t *testing.T) {
+	db := setUpDB()
+	defer truncateApiKey(db)
+	router := setUpRouter(db)
+
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/apikeys", `{"name": "reader", "owner": "web team", "scopes": ["categories:read"]}`)
+	assert.Equal(t, http.StatusOK, code)
+	key := created["data"].(map[string]interface{})["key"].(string)
+
+	code, _ = sendWithKey(router, key, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, body := sendWithKey(router, key, http.MethodDelete, "/api/categories/1", "")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "missing scope categories:delete", body["data"])
+
+	code, _ = sendWithKey(router, "", http.MethodDelete, "/api/categories/1", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
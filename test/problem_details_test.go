@@ -0,0 +1,94 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/exception"
+	"golang-restful-api/helper"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func readProblem(t *testing.T, response *http.Response) map[string]interface{} {
+	assert.Equal(t, "application/problem+json", response.Header.Get("Content-Type"))
+
+	body, errReadAll := io.ReadAll(response.Body)
+	helper.PanicIfError(errReadAll)
+
+	var problem map[string]interface{}
+	errUnmarshal := json.Unmarshal(body, &problem)
+	helper.PanicIfError(errUnmarshal)
+
+	return problem
+}
+
+func TestProblemNegotiatedWithAcceptHeader(t *testing.T) {
+	db := setUpDB()
+	defer truncateCategory(db)
+	router := setUpRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/404?x=1", nil)
+	request.Header.Add("Accept", "application/json;q=0.5, application/problem+json")
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	problem := readProblem(t, response)
+	assert.Equal(t, "/problems/not-found", problem["type"])
+	assert.Equal(t, "Not Found", problem["title"])
+	assert.Equal(t, http.StatusNotFound, int(problem["status"].(float64)))
+	assert.Equal(t, "category not found", problem["detail"])
+	assert.Equal(t, "/api/categories/404?x=1", problem["instance"])
+}
+
+func TestProblemValidationErrors(t *testing.T) {
+	db := setUpDB()
+	defer truncateCategory(db)
+	router := setUpRouterWithErrorFormat(db, exception.FormatProblem)
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name" : ""}`))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	problem := readProblem(t, response)
+	assert.Equal(t, "/problems/validation", problem["type"])
+	assert.Equal(t, http.StatusBadRequest, int(problem["status"].(float64)))
+
+	fieldErrors := problem["errors"].([]interface{})
+	assert.Len(t, fieldErrors, 1)
+	assert.Equal(t, "name", fieldErrors[0].(map[string]interface{})["field"])
+	assert.Equal(t, "required", fieldErrors[0].(map[string]interface{})["rule"])
+}
+
+func TestProblemConfiguredGloballyForUnauthorized(t *testing.T) {
+	db := setUpDB()
+	defer truncateCategory(db)
+	router := setUpRouterWithErrorFormat(db, exception.FormatProblem)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("X-API-Key", "SALAH")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	problem := readProblem(t, response)
+	assert.Equal(t, "/problems/unauthorized", problem["type"])
+	assert.Equal(t, "Unauthorized", problem["title"])
+	assert.NotContains(t, problem, "detail")
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/auth"
+	"golang-restful-api/config"
+	"golang-restful-api/middleware"
+	"golang-restful-api/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := ratelimit.NewMemoryStore()
+	store.Now = clock.Now
+	limit := ratelimit.Limit{Requests: 1, Per: time.Second, Burst: 2}
+	ctx := context.Background()
+
+	result, err := store.Take(ctx, "client", limit)
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 1, result.Remaining)
+	assert.Equal(t, time.Second, result.Reset)
+
+	result, _ = store.Take(ctx, "client", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, _ = store.Take(ctx, "client", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	result, _ = store.Take(ctx, "other", limit)
+	assert.True(t, result.Allowed)
+
+	clock.now = clock.now.Add(time.Second)
+	result, _ = store.Take(ctx, "client", limit)
+	assert.True(t, result.Allowed)
+}
+
+func TestRateLimitRouteMatching(t *testing.T) {
+	rules := ratelimit.Rules{Routes: map[string]ratelimit.Limit{
+		"GET /api/categories/:categoryId": {Requests: 1, Per: time.Second},
+	}}
+
+	route, _, ok := rules.RouteLimit(http.MethodGet, "/api/categories/5")
+	assert.True(t, ok)
+	assert.Equal(t, "GET /api/categories/:categoryId", route)
+
+	_, _, ok = rules.RouteLimit(http.MethodGet, "/api/categories")
+	assert.False(t, ok)
+
+	_, _, ok = rules.RouteLimit(http.MethodGet, "/api/categories/5/children")
+	assert.False(t, ok)
+
+	_, _, ok = rules.RouteLimit(http.MethodDelete, "/api/categories/5")
+	assert.False(t, ok)
+
+	rules.Routes["GET /api/categories/tree"] = ratelimit.Limit{Requests: 2, Per: time.Second}
+	route, _, ok = rules.RouteLimit(http.MethodGet, "/api/categories/tree")
+	assert.True(t, ok)
+	assert.Equal(t, "GET /api/categories/tree", route)
+}
+
+func setUpRateLimitHandler(rules ratelimit.Rules, clientIPHeader string) http.Handler {
+	ok := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+
+	handler := middleware.NewRateLimitMiddleware(ok, ratelimit.NewMemoryStore(), rules, app.HealthPaths...)
+
+	return middleware.NewClientIpMiddleware(handler, clientIPHeader)
+}
+
+func sendFrom(handler http.Handler, remoteAddr string, method string, target string, header http.Header) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, "http://localhost:3000"+target, nil)
+	request.RemoteAddr = remoteAddr
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	handler := setUpRateLimitHandler(ratelimit.Rules{Default: ratelimit.Limit{Requests: 2, Per: time.Minute}}, "")
+
+	recorder := sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", recorder.Header().Get("RateLimit-Reset"))
+
+	sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories", nil)
+	recorder = sendFrom(handler, "10.0.0.1:5678", http.MethodGet, "/api/categories", nil)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+
+	var body map[string]interface{}
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&body))
+	assert.Equal(t, float64(http.StatusTooManyRequests), body["code"])
+	assert.Equal(t, "Too Many Requests", body["status"])
+	assert.Equal(t, "rate limit exceeded, retry in 30s", body["data"])
+
+	recorder = sendFrom(handler, "10.0.0.2:1234", http.MethodGet, "/api/categories", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/healthz", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitPerRoute(t *testing.T) {
+	handler := setUpRateLimitHandler(ratelimit.Rules{
+		Default: ratelimit.Limit{Requests: 100, Per: time.Minute},
+		Routes: map[string]ratelimit.Limit{
+			"GET /api/categories": {Requests: 1, Per: time.Minute},
+		},
+	}, "X-Forwarded-For")
+	forwarded := http.Header{"X-Forwarded-For": {"203.0.113.9, 10.0.0.7"}}
+
+	recorder := sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories", forwarded)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+
+	recorder = sendFrom(handler, "10.0.0.2:1234", http.MethodGet, "/api/categories", forwarded)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	// A client cannot get a fresh bucket with its own header line when the
+	// proxy adds another line rather than extending it.
+	spoofed := http.Header{"X-Forwarded-For": {"198.51.100.1", "203.0.113.9, 10.0.0.7", " "}}
+	recorder = sendFrom(handler, "10.0.0.2:1234", http.MethodGet, "/api/categories", spoofed)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	recorder = sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories/1", forwarded)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "96", recorder.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitRejectedCredentials(t *testing.T) {
+	ok := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+	store := ratelimit.NewMemoryStore()
+	rules := ratelimit.Rules{Default: ratelimit.Limit{Requests: 3, Per: time.Minute}}
+
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewRateLimitMiddleware(ok, store, rules), []auth.Authenticator{auth.NewStaticKeyAuthenticator("RAHASIA")})
+	authMiddleware.Unauthorized = middleware.NewRateLimitMiddleware(authMiddleware.Unauthorized, store, rules)
+	handler := middleware.NewClientIpMiddleware(authMiddleware, "")
+
+	for i := 0; i < 3; i++ {
+		recorder := sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories", http.Header{"X-Api-Key": {"guess"}})
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	}
+
+	recorder := sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories", http.Header{"X-Api-Key": {"guess"}})
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	recorder = sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories", nil)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	// Valid credentials are limited by identity, not by the failures of
+	// their IP.
+	recorder = sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories", http.Header{"X-Api-Key": {"RAHASIA"}})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+type failingStore struct{}
+
+func (store failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unreachable")
+}
+
+func TestRateLimitStoreFailureLetsRequestsThrough(t *testing.T) {
+	ok := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+	handler := middleware.NewRateLimitMiddleware(ok, failingStore{}, ratelimit.Rules{Default: ratelimit.Limit{Requests: 1, Per: time.Minute}})
+
+	recorder := sendFrom(handler, "10.0.0.1:1234", http.MethodGet, "/api/categories", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitRulesRejectUnknownRoute(t *testing.T) {
+	cfg := config.Default().RateLimit
+	cfg.Routes = map[string]config.LimitConfig{"GET /api/unknown": {Requests: 1, Per: time.Second}}
+
+	_, err := app.NewRateLimitRules(cfg)
+	assert.EqualError(t, err, `config: rate_limit.routes: unknown route "GET /api/unknown"`)
+}
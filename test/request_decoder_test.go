@@ -0,0 +1,94 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/controller"
+	"golang-restful-api/health"
+	"golang-restful-api/middleware"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setUpDecoderRouter(strict bool) http.Handler {
+	categoryService := service.NewCategoryService(repository.NewMemoryCategoryRepository(), repository.NewMemoryAuditRepository(), repository.NewMemoryDatabase(), validator.New(), service.NameRuleNormalized, testPageSize, testHierarchy)
+	categoryController := controller.NewCategoryController(categoryService, controller.NewRequestDecoder(64, strict))
+
+	router := app.NewRouter(categoryController, controller.NewApiKeyController(nil, nil), controller.NewUserController(nil, nil), controller.NewAuditController(nil), controller.NewHealthController(health.NewHealth(time.Second)))
+
+	return middleware.NewAuthMiddleware(router, staticKey("RAHASIA"), app.PublicPaths...)
+}
+
+func TestRequestDecodingErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		strict      bool
+		code        int
+		message     string
+	}{
+		{"empty body", http.MethodPost, "/api/categories", "application/json", "", false, http.StatusBadRequest, "request body must not be empty"},
+		{"syntax error", http.MethodPost, "/api/categories", "application/json", `{"name": "a",}`, false, http.StatusBadRequest, "request body contains malformed JSON at offset 14"},
+		{"truncated", http.MethodPost, "/api/categories", "application/json", `{"name": "a"`, false, http.StatusBadRequest, "request body contains malformed JSON"},
+		{"wrong type", http.MethodPost, "/api/categories", "application/json", `{"name": 1}`, false, http.StatusBadRequest, `field "name" must be of type string, not number`},
+		{"not an object", http.MethodPost, "/api/categories", "application/json", `[]`, false, http.StatusBadRequest, "request body must be a JSON object, not array"},
+		{"two values", http.MethodPost, "/api/categories", "application/json", `{"name": "a"} {}`, false, http.StatusBadRequest, "request body must contain a single JSON value"},
+		{"too large", http.MethodPost, "/api/categories", "application/json", `{"name": "` + strings.Repeat("a", 100) + `"}`, false, http.StatusBadRequest, "request body must not be larger than 64 bytes"},
+		{"unknown field strict", http.MethodPost, "/api/categories", "application/json", `{"name": "a", "color": "red"}`, true, http.StatusBadRequest, `request body contains unknown field "color"`},
+		{"missing content type", http.MethodPost, "/api/categories", "", `{"name": "a"}`, false, http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+		{"wrong content type", http.MethodPut, "/api/categories/1", "text/plain", `{"name": "a"}`, false, http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+		{"non-numeric id", http.MethodGet, "/api/categories/abc", "", "", false, http.StatusBadRequest, "categoryId must be an integer"},
+		{"id out of range", http.MethodDelete, "/api/categories/99999999999999999999", "", "", false, http.StatusBadRequest, "categoryId is out of range"},
+		{"id above int column", http.MethodGet, "/api/categories/2147483648", "", "", false, http.StatusBadRequest, "categoryId is out of range"},
+		{"negative id", http.MethodGet, "/api/categories/-1", "", "", false, http.StatusBadRequest, "categoryId must be a positive integer"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := setUpDecoderRouter(test.strict)
+
+			request := httptest.NewRequest(test.method, "http://localhost:3000"+test.target, strings.NewReader(test.body))
+			request.Header.Add("X-API-Key", "RAHASIA")
+			if test.contentType != "" {
+				request.Header.Add("Content-Type", test.contentType)
+			}
+			recorder := httptest.NewRecorder()
+
+			router.ServeHTTP(recorder, request)
+
+			response := recorder.Result()
+			assert.Equal(t, test.code, response.StatusCode)
+
+			body, err := io.ReadAll(response.Body)
+			assert.Nil(t, err)
+
+			var responseBody map[string]interface{}
+			assert.Nil(t, json.Unmarshal(body, &responseBody))
+			assert.Equal(t, test.message, responseBody["data"])
+		})
+	}
+}
+
+func TestRequestDecodingLenientIgnoresUnknownFields(t *testing.T) {
+	router := setUpDecoderRouter(false)
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "a", "color": "red"}`))
+	request.Header.Add("Content-Type", "application/json; charset=utf-8")
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+}
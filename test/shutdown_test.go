@@ -0,0 +1,100 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/config"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+
+	return &output
+}
+
+func TestShutdownHooksRunInReverseOrder(t *testing.T) {
+	output := captureLog(t)
+	shutdown := app.NewShutdown(time.Second)
+
+	var order []string
+	shutdown.Register("first", func(ctx context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+	shutdown.Register("second", func(ctx context.Context) error {
+		order = append(order, "second")
+		return errors.New("failed")
+	})
+
+	err := shutdown.Run()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"second", "first"}, order)
+	assert.True(t, shutdown.IsShuttingDown())
+	assert.Nil(t, shutdown.Run())
+	// Commands that do not serve exit quietly.
+	assert.Empty(t, output.String())
+}
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = writer.Write([]byte("done"))
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	output := captureLog(t)
+	server := app.NewServer(config.Default().Server, handler)
+	shutdown := app.NewShutdown(5 * time.Second)
+
+	databaseClosed := false
+	shutdown.Register("database", func(ctx context.Context) error {
+		databaseClosed = true
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- app.Serve(ctx, server, listener, shutdown)
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer response.Body.Close()
+
+		content, _ := io.ReadAll(response.Body)
+		body <- string(content)
+	}()
+
+	<-started
+	cancel()
+
+	assert.Nil(t, <-served)
+	assert.Equal(t, "done", <-body)
+	assert.True(t, databaseClosed)
+	assert.Contains(t, output.String(), "shutdown: stopping database")
+}
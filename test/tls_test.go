@@ -0,0 +1,237 @@
+package test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/auth"
+	"golang-restful-api/config"
+	"golang-restful-api/middleware"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	der         []byte
+}
+
+func issueCertificate(t *testing.T, serial int64, commonName string, parent *testCertificate, usage x509.ExtKeyUsage) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.certificate, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.Nil(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	return &testCertificate{certificate: certificate, key: key, der: der}
+}
+
+func (certificate *testCertificate) pair() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{certificate.der}, PrivateKey: certificate.key}
+}
+
+func (certificate *testCertificate) write(t *testing.T, certFile string, keyFile string) {
+	err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.der}), 0600)
+	assert.Nil(t, err)
+
+	if keyFile != "" {
+		der, err := x509.MarshalECPrivateKey(certificate.key)
+		assert.Nil(t, err)
+		err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600)
+		assert.Nil(t, err)
+	}
+
+	// Rewrites within the file system's timestamp granularity must still be
+	// seen as changes.
+	later := time.Now().Add(time.Duration(certificate.certificate.SerialNumber.Int64()) * time.Second)
+	assert.Nil(t, os.Chtimes(certFile, later, later))
+}
+
+func setUpTLSServer(t *testing.T, tlsConfig config.TLSConfig, handler http.Handler) (*app.TLSReloader, string) {
+	reloader, err := app.NewTLSReloader(tlsConfig)
+	assert.Nil(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	server := app.NewServer(config.Default().Server, handler)
+	server.TLSConfig = reloader.TLSConfig()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- app.Serve(ctx, server, listener, app.NewShutdown(time.Second))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+
+	return reloader, "https://" + listener.Addr().String()
+}
+
+func tlsClient(ca *testCertificate, certificates ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.certificate)
+
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool, Certificates: certificates},
+		DisableKeepAlives: true,
+	}}
+}
+
+func servedSerial(t *testing.T, client *http.Client, url string) int64 {
+	response, err := client.Get(url)
+	if !assert.Nil(t, err) {
+		return 0
+	}
+	defer response.Body.Close()
+
+	return response.TLS.PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestTLSReloadsChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	tlsConfig := config.Default().Server.TLS
+	tlsConfig.Enabled = true
+	tlsConfig.CertFile = filepath.Join(dir, "server.crt")
+	tlsConfig.KeyFile = filepath.Join(dir, "server.key")
+
+	ca := issueCertificate(t, 1, "test ca", nil, x509.ExtKeyUsageServerAuth)
+	issueCertificate(t, 2, "localhost", ca, x509.ExtKeyUsageServerAuth).write(t, tlsConfig.CertFile, tlsConfig.KeyFile)
+
+	ok := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {})
+	reloader, url := setUpTLSServer(t, tlsConfig, ok)
+	client := tlsClient(ca)
+
+	assert.Equal(t, int64(2), servedSerial(t, client, url))
+
+	reloaded, err := reloader.Reload()
+	assert.Nil(t, err)
+	assert.False(t, reloaded)
+
+	issueCertificate(t, 3, "localhost", ca, x509.ExtKeyUsageServerAuth).write(t, tlsConfig.CertFile, tlsConfig.KeyFile)
+	reloaded, err = reloader.Reload()
+	assert.Nil(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, int64(3), servedSerial(t, client, url))
+
+	// A certificate without its new key is rejected and the old pair stays.
+	issueCertificate(t, 4, "localhost", ca, x509.ExtKeyUsageServerAuth).write(t, tlsConfig.CertFile, "")
+	reloaded, err = reloader.Reload()
+	assert.NotNil(t, err)
+	assert.False(t, reloaded)
+	assert.Equal(t, int64(3), servedSerial(t, client, url))
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	dir := t.TempDir()
+	tlsConfig := config.Default().Server.TLS
+	tlsConfig.Enabled = true
+	tlsConfig.CertFile = filepath.Join(dir, "server.crt")
+	tlsConfig.KeyFile = filepath.Join(dir, "server.key")
+	tlsConfig.MinVersion = "1.3"
+
+	ca := issueCertificate(t, 1, "test ca", nil, x509.ExtKeyUsageServerAuth)
+	issueCertificate(t, 2, "localhost", ca, x509.ExtKeyUsageServerAuth).write(t, tlsConfig.CertFile, tlsConfig.KeyFile)
+
+	ok := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {})
+	_, url := setUpTLSServer(t, tlsConfig, ok)
+
+	client := tlsClient(ca)
+	client.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS12
+	_, err := client.Get(url)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, int64(2), servedSerial(t, tlsClient(ca), url))
+}
+
+func TestTLSRejectsUnknownCipherSuite(t *testing.T) {
+	tlsConfig := config.Default().Server.TLS
+	tlsConfig.CipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_RSA_WITH_RC4_128_SHA"}
+
+	_, err := app.NewTLSReloader(tlsConfig)
+	assert.EqualError(t, err, `config: server.tls.cipher_suites: unknown or insecure cipher suite "TLS_RSA_WITH_RC4_128_SHA"`)
+}
+
+func TestTLSClientCertificateIdentity(t *testing.T) {
+	dir := t.TempDir()
+	tlsConfig := config.Default().Server.TLS
+	tlsConfig.Enabled = true
+	tlsConfig.CertFile = filepath.Join(dir, "server.crt")
+	tlsConfig.KeyFile = filepath.Join(dir, "server.key")
+	tlsConfig.ClientCAFile = filepath.Join(dir, "clients.crt")
+	tlsConfig.ClientAuth = "optional"
+
+	ca := issueCertificate(t, 1, "test ca", nil, x509.ExtKeyUsageServerAuth)
+	issueCertificate(t, 2, "localhost", ca, x509.ExtKeyUsageServerAuth).write(t, tlsConfig.CertFile, tlsConfig.KeyFile)
+	clientCA := issueCertificate(t, 3, "client ca", nil, x509.ExtKeyUsageClientAuth)
+	clientCA.write(t, tlsConfig.ClientCAFile, "")
+
+	billing := issueCertificate(t, 4, "billing-service", clientCA, x509.ExtKeyUsageClientAuth)
+	unmapped := issueCertificate(t, 5, "reporting-service", clientCA, x509.ExtKeyUsageClientAuth)
+	untrusted := issueCertificate(t, 6, "billing-service", issueCertificate(t, 7, "other ca", nil, x509.ExtKeyUsageClientAuth), x509.ExtKeyUsageClientAuth)
+
+	identity := &auth.Identity{}
+	authenticators := append([]auth.Authenticator{
+		auth.NewClientCertAuthenticator(map[string][]string{"billing-service": {auth.ScopeCategoriesRead}}),
+	}, staticKey("RAHASIA")...)
+	handler := middleware.NewAuthMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		*identity, _ = auth.IdentityFromContext(request.Context())
+	}), authenticators)
+	_, url := setUpTLSServer(t, tlsConfig, handler)
+
+	response, err := tlsClient(ca, billing.pair()).Get(url)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, auth.Identity{Type: "client_cert", Subject: "billing-service", Name: "billing-service", Scopes: []string{auth.ScopeCategoriesRead}}, *identity)
+
+	response, err = tlsClient(ca, unmapped.pair()).Get(url)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	request, _ := http.NewRequest(http.MethodGet, url, nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	response, err = tlsClient(ca).Do(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "static_key", identity.Type)
+
+	// The client only offers certificates issued by a CA the server accepts.
+	response, err = tlsClient(ca, untrusted.pair()).Get(url)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
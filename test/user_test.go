@@ -0,0 +1,306 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-restful-api/app"
+	"golang-restful-api/auth"
+	"golang-restful-api/config"
+	"golang-restful-api/helper"
+	"golang-restful-api/model/web"
+	"golang-restful-api/repository"
+	"golang-restful-api/service"
+	"golang.org/x/crypto/bcrypt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setUpUserService(db *sql.DB, issuer auth.TokenIssuer) service.UserService {
+	policy := service.LoginPolicy{
+		SessionTTL:      time.Hour,
+		MaxFailedLogins: 3,
+		LockoutDuration: time.Minute,
+		BcryptCost:      bcrypt.MinCost,
+		Issuer:          issuer,
+	}
+
+	return service.NewUserService(repository.NewUserRepository(setUpDialect()), repository.NewUserSessionRepository(setUpDialect()),
+		repository.NewSqlDatabase(db), app.NewValidator(), policy)
+}
+
+func truncateUsers(db *sql.DB) {
+	_, err := db.Exec("DELETE FROM user_session")
+	helper.PanicIfError(err)
+
+	_, err = db.Exec("DELETE FROM users")
+	helper.PanicIfError(err)
+}
+
+func sendWithToken(router http.Handler, token string, method string, target string, body string) (int, map[string]interface{}) {
+	request := httptest.NewRequest(method, "http://localhost:3000"+target, strings.NewReader(body))
+	request.Header.Add("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Add("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	var result map[string]interface{}
+	_ = json.NewDecoder(recorder.Result().Body).Decode(&result)
+
+	return recorder.Result().StatusCode, result
+}
+
+func createUser(t *testing.T, router http.Handler, body string) string {
+	code, created := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/users", body)
+	assert.Equal(t, http.StatusOK, code)
+
+	return strconv.Itoa(int(created["data"].(map[string]interface{})["id"].(float64)))
+}
+
+func login(router http.Handler, username string, password string) (int, map[string]interface{}) {
+	return sendWithToken(router, "", http.MethodPost, "/api/auth/login", `{"username": "`+username+`", "password": "`+password+`"}`)
+}
+
+func loginToken(t *testing.T, router http.Handler, username string, password string) string {
+	code, body := login(router, username, password)
+	assert.Equal(t, http.StatusOK, code)
+
+	return body["data"].(map[string]interface{})["token"].(string)
+}
+
+func TestUserLoginAndLogout(t *testing.T) {
+	db := setUpDB()
+	defer truncateUsers(db)
+	router := setUpRouter(db)
+
+	createUser(t, router, `{"username": "Alice", "password": "correct horse", "scopes": ["categories:read"]}`)
+
+	code, body := login(router, "alice", "correct horse")
+	assert.Equal(t, http.StatusOK, code)
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, "Bearer", data["token_type"])
+	assert.Equal(t, "alice", data["user"].(map[string]interface{})["username"])
+	token := data["token"].(string)
+
+	code, _ = sendWithToken(router, token, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithToken(router, token, http.MethodDelete, "/api/categories/1", "")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = sendWithToken(router, token, http.MethodPost, "/api/auth/logout", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithToken(router, token, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestUserLoginRejected(t *testing.T) {
+	db := setUpDB()
+	defer truncateUsers(db)
+	router := setUpRouter(db)
+
+	createUser(t, router, `{"username": "bob", "password": "correct horse"}`)
+
+	code, wrongPassword := login(router, "bob", "battery staple")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, unknownUser := login(router, "mallory", "battery staple")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, wrongPassword["data"], unknownUser["data"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/users", `{"username": "BOB", "password": "another password"}`)
+	assert.Equal(t, http.StatusConflict, code)
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/users", `{"username": "carol", "password": "short"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestUserRegistration(t *testing.T) {
+	db := setUpDB()
+	defer truncateUsers(db)
+	router := setUpRouter(db)
+
+	// The route is public but closed unless registration is enabled.
+	code, body := sendWithToken(router, "", http.MethodPost, "/api/auth/register", `{"username": "erin", "password": "correct horse"}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "registration is disabled, ask an admin for an account", body["data"])
+
+	policy := service.LoginPolicy{
+		SessionTTL:         time.Hour,
+		MaxFailedLogins:    3,
+		LockoutDuration:    time.Minute,
+		BcryptCost:         bcrypt.MinCost,
+		Registration:       true,
+		RegistrationScopes: []string{auth.ScopeCategoriesRead},
+	}
+	userService := service.NewUserService(repository.NewUserRepository(setUpDialect()), repository.NewUserSessionRepository(setUpDialect()),
+		repository.NewSqlDatabase(db), app.NewValidator(), policy)
+
+	user, err := userService.Register(context.Background(), web.RegisterRequest{Username: "Erin", Password: "correct horse"})
+	assert.Nil(t, err)
+	assert.Equal(t, "erin", user.Username)
+	assert.Equal(t, []string{auth.ScopeCategoriesRead}, user.Scopes)
+
+	_, err = userService.Register(context.Background(), web.RegisterRequest{Username: "erin", Password: "another password"})
+	assert.NotNil(t, err)
+
+	token := loginToken(t, router, "erin", "correct horse")
+	code, _ = sendWithToken(router, token, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = sendWithToken(router, token, http.MethodGet, "/api/users", "")
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestUserLockedAfterFailedLogins(t *testing.T) {
+	db := setUpDB()
+	defer truncateUsers(db)
+	router := setUpRouter(db)
+
+	id := createUser(t, router, `{"username": "dave", "password": "correct horse"}`)
+
+	for i := 0; i < 3; i++ {
+		code, _ := login(router, "dave", "battery staple")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	}
+
+	// A locked account looks like any other to a wrong password.
+	code, body := login(router, "dave", "battery staple")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "invalid username or password", body["data"])
+
+	code, body = login(router, "dave", "correct horse")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.True(t, strings.HasPrefix(body["data"].(string), "account is locked until "))
+
+	code, body = sendWithKey(router, "RAHASIA", http.MethodGet, "/api/users/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, body["data"].(map[string]interface{})["locked_until"])
+
+	code, _ = sendWithKey(router, "RAHASIA", http.MethodPost, "/api/users/"+id+"/unlock", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = login(router, "dave", "correct horse")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestUserRecordFailedLoginOnlyTouchesLockout(t *testing.T) {
+	db := setUpDB()
+	defer truncateUsers(db)
+	ctx := context.Background()
+	userRepository := repository.NewUserRepository(setUpDialect())
+	database := repository.NewSqlDatabase(db)
+
+	created, err := setUpUserService(db, nil).Create(ctx, web.UserCreateRequest{Username: "frank", Password: "correct horse", Scopes: []string{auth.ScopeCategoriesRead}})
+	assert.Nil(t, err)
+
+	tx, err := database.Begin(ctx)
+	assert.Nil(t, err)
+	defer tx.Rollback()
+	before, err := userRepository.FindById(ctx, tx, created.Id)
+	assert.Nil(t, err)
+
+	lockedUntil := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	for i := 1; i <= 3; i++ {
+		assert.Nil(t, userRepository.RecordFailedLogin(ctx, tx, created.Id, 3, lockedUntil))
+
+		user, err := userRepository.FindById(ctx, tx, created.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, before.PasswordHash, user.PasswordHash)
+		assert.Equal(t, before.Scopes, user.Scopes)
+		if i < 3 {
+			assert.Equal(t, i, user.FailedLogins)
+			assert.True(t, user.LockedUntil.IsZero())
+		} else {
+			assert.Equal(t, 0, user.FailedLogins)
+			assert.True(t, lockedUntil.Equal(user.LockedUntil))
+		}
+	}
+}
+
+func TestUserChangePasswordRevokesOtherSessions(t *testing.T) {
+	db := setUpDB()
+	defer truncateUsers(db)
+	router := setUpRouter(db)
+
+	createUser(t, router, `{"username": "erin", "password": "correct horse", "scopes": ["categories:read"]}`)
+	current := loginToken(t, router, "erin", "correct horse")
+	other := loginToken(t, router, "erin", "correct horse")
+
+	code, _ := sendWithToken(router, current, http.MethodPut, "/api/auth/password", `{"current_password": "wrong password", "new_password": "battery staple"}`)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = sendWithToken(router, current, http.MethodPut, "/api/auth/password", `{"current_password": "correct horse", "new_password": "battery staple"}`)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithToken(router, current, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = sendWithToken(router, other, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = login(router, "erin", "correct horse")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = login(router, "erin", "battery staple")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestUserSessionNeededForLogout(t *testing.T) {
+	db := setUpDB()
+	router := setUpRouter(db)
+
+	code, body := sendWithKey(router, "RAHASIA", http.MethodPost, "/api/auth/logout", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "request must be authenticated with a session token", body["data"])
+}
+
+func TestUserJWTSessions(t *testing.T) {
+	db := setUpDB()
+	defer truncateUsers(db)
+
+	jwtConfig := config.JWTConfig{Enabled: true, Secret: "shared-secret", Issuer: "golang-restful-api"}
+	userService := setUpUserService(db, auth.NewJWTIssuer(jwtConfig))
+	ctx := context.Background()
+
+	_, err := userService.Create(ctx, web.UserCreateRequest{Username: "frank", Password: "correct horse", Scopes: []string{auth.ScopeCategoriesWrite}})
+	assert.Nil(t, err)
+
+	login, err := userService.Login(ctx, web.LoginRequest{Username: "frank", Password: "correct horse"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, strings.Count(login.Token, "."))
+
+	authenticator, err := auth.NewJWTAuthenticator(jwtConfig)
+	assert.Nil(t, err)
+	authenticator.Sessions = userService
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("Authorization", "Bearer "+login.Token)
+
+	identity, err := authenticator.Authenticate(request)
+	assert.Nil(t, err)
+	assert.Equal(t, "frank", identity.Name)
+	assert.Equal(t, []string{auth.ScopeCategoriesWrite}, identity.Scopes)
+	assert.NotEmpty(t, identity.Session)
+
+	// Scopes taken away after login no longer apply to the token.
+	_, err = db.Exec(setUpDialect().Rebind("UPDATE users SET scopes = ? WHERE username = ?"), auth.ScopeCategoriesRead, "frank")
+	assert.Nil(t, err)
+	identity, err = authenticator.Authenticate(request)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{auth.ScopeCategoriesRead}, identity.Scopes)
+
+	err = userService.Logout(auth.WithIdentity(ctx, identity))
+	assert.Nil(t, err)
+
+	_, err = authenticator.Authenticate(request)
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}